@@ -0,0 +1,185 @@
+// altscreen.go — the alternate screen buffer and full-screen sessions.
+// Copyright (C) 2025 R. S. Doiel
+package termlib
+
+import (
+	"io"
+	"strings"
+)
+
+/** EnterAltScreen switches the terminal to its alternate screen, a separate
+ * page without scrollback. Whatever a full-screen application draws there
+ * disappears on ExitAltScreen, leaving the user's shell session and
+ * scrollback as they were. The switch is written immediately; the next
+ * Refresh repaints the frame on the new screen.
+ *
+ * Example:
+ *   term.EnterAltScreen()
+ *   defer term.ExitAltScreen()
+ */
+func (t *Terminal) EnterAltScreen() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.altScreen || t.output == OutputLines {
+		return
+	}
+	// Each screen keeps its own kitty keyboard mode, so move it across.
+	t.write([]byte(t.kittyPopSeq() + "\033[?1049h" + t.kittyPushSeq()))
+	t.altScreen = true
+	t.screenSwitched()
+	t.trackModes()
+}
+
+// ExitAltScreen returns to the normal screen, restoring what it showed
+// before EnterAltScreen.
+func (t *Terminal) ExitAltScreen() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.altScreen {
+		return
+	}
+	t.write([]byte(t.kittyPopSeq() + "\033[?1049l" + t.kittyPushSeq()))
+	t.altScreen = false
+	t.screenSwitched()
+	t.trackModes()
+}
+
+// InAltScreen reports whether the alternate screen is in use.
+func (t *Terminal) InAltScreen() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.altScreen
+}
+
+// screenSwitched forgets the screen contents after switching screens, so
+// the next Refresh draws the whole frame on the screen now showing.
+func (t *Terminal) screenSwitched() {
+	if t.drawn {
+		t.invalidate()
+	}
+	t.render.row, t.render.col = 0, 0
+}
+
+/** FullScreen runs fn as a full-screen session: the input is put in raw
+ * mode, the terminal switches to the alternate screen and the cursor is
+ * hidden. When fn returns or panics, the cursor is shown again, mouse
+ * reporting is turned off, the normal screen comes back and raw mode is
+ * left; a panic then continues. These modes are also restored if the
+ * process receives a termination signal (see RestoreTerminal).
+ *
+ * Parameters:
+ *   in (io.Reader) — terminal input to put in raw mode, typically os.Stdin.
+ *   fn (func() error) — draws and handles input until the session ends.
+ *
+ * Returns:
+ *   error — from entering raw mode, or the error fn returned.
+ *
+ * Example:
+ *   err := term.FullScreen(os.Stdin, func() error {
+ *       term.Print("Press q to quit")
+ *       term.Refresh()
+ *       for k := range termlib.KeyReader(os.Stdin) {
+ *           if k == termlib.Key('q') { break }
+ *       }
+ *       return nil
+ *   })
+ */
+func (t *Terminal) FullScreen(in io.Reader, fn func() error) error {
+	restoreRaw, err := EnterRawMode(in)
+	if err != nil {
+		return err
+	}
+	defer restoreRaw()
+	defer t.restoreModes()
+	t.EnterAltScreen()
+	t.HideCursor()
+	return fn()
+}
+
+// restoreModes immediately shows the cursor, gives it back its own shape
+// and color, turns off mouse reporting and the kitty keyboard protocol and
+// leaves the alternate screen, undoing whichever of these modes are in
+// effect.
+func (t *Terminal) restoreModes() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var seq strings.Builder
+	t.cursorHidden = false
+	if t.termCurHidden {
+		seq.WriteString("\033[?25h")
+		t.termCurHidden = false
+	}
+	t.cursorShape, t.cursorColor = CursorDefault, DefaultColor
+	seq.WriteString(t.resetCursorLookSeq())
+	seq.WriteString(t.mouseModeSeq(MouseOff))
+	seq.WriteString(t.kittyPopSeq())
+	t.kittyFlags = 0
+	if t.altScreen {
+		seq.WriteString("\033[?1049l")
+		t.altScreen = false
+		t.screenSwitched()
+	}
+	if seq.Len() > 0 {
+		t.write([]byte(seq.String()))
+	}
+	t.trackModes()
+}
+
+// suspendModes undoes the modes restoreModes would, but remembers them so
+// that resumeModes can set them again.
+func (t *Terminal) suspendModes() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var seq strings.Builder
+	if t.termCurHidden {
+		seq.WriteString("\033[?25h")
+		t.termCurHidden = false
+	}
+	seq.WriteString(t.resetCursorLookSeq())
+	if p, ok := mouseModeParams[t.mouseMode]; ok {
+		seq.WriteString("\033[?" + p + "l\033[?1006l")
+	}
+	seq.WriteString(t.kittyPopSeq())
+	if t.altScreen {
+		seq.WriteString("\033[?1049l")
+	}
+	if seq.Len() > 0 {
+		t.write([]byte(seq.String()))
+	}
+}
+
+// resumeModes sets the modes remembered by suspendModes again and
+// forgets the screen contents, which may have been changed while the
+// process was stopped, so that the next Refresh repaints everything. The
+// cursor's visibility, shape and color are sent again by that Refresh.
+func (t *Terminal) resumeModes() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var seq strings.Builder
+	if t.altScreen {
+		seq.WriteString("\033[?1049h")
+	}
+	seq.WriteString(t.kittyPushSeq())
+	if p, ok := mouseModeParams[t.mouseMode]; ok {
+		seq.WriteString("\033[?" + p + "h\033[?1006h")
+	}
+	if seq.Len() > 0 {
+		t.write([]byte(seq.String()))
+	}
+	t.invalidate()
+}
+
+// trackModes keeps the Terminal registered with RestoreTerminal while any
+// of the modes undone by restoreModes is in effect. t.mu must be held.
+func (t *Terminal) trackModes() {
+	active := t.termCurHidden || t.altScreen || t.mouseMode != MouseOff ||
+		t.termCurShape != CursorDefault || !t.termCurColor.IsDefault() ||
+		t.kittyFlags != 0
+	switch {
+	case active && t.restoreEntry == nil:
+		t.restoreEntry = registerRestore(t.restoreModes, t.suspendModes, t.resumeModes)
+	case !active && t.restoreEntry != nil:
+		t.restoreEntry.unregister()
+		t.restoreEntry = nil
+	}
+}
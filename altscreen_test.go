@@ -0,0 +1,90 @@
+// altscreen_test.go — tests for the alternate screen and full-screen sessions.
+// Copyright (C) 2025 R. S. Doiel
+package termlib
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestAltScreen(t *testing.T) {
+	var out bytes.Buffer
+	term := New(&out)
+	term.SetSize(10, 2)
+	term.Print("hi")
+	term.Refresh()
+	out.Reset()
+
+	term.EnterAltScreen()
+	term.EnterAltScreen() // already there: no output
+	if !term.InAltScreen() {
+		t.Error("InAltScreen false after EnterAltScreen")
+	}
+	term.Refresh()
+	if got := out.String(); !strings.HasPrefix(got, "\033[?1049h") || !strings.Contains(got, "hi") {
+		t.Errorf("frame not repainted on the alternate screen: %q", got)
+	}
+	out.Reset()
+	term.ExitAltScreen()
+	if got := out.String(); got != "\033[?1049l" {
+		t.Errorf("exit: got %q", got)
+	}
+}
+
+func TestFullScreenRestores(t *testing.T) {
+	var out bytes.Buffer
+	term := New(&out)
+	term.SetSize(10, 2)
+	raw := false
+	in := rawReader{strings.NewReader(""), &raw}
+
+	wantErr := errors.New("done")
+	err := term.FullScreen(in, func() error {
+		if !raw || !term.InAltScreen() {
+			t.Error("session not set up")
+		}
+		term.EnableMouse(MouseClicks)
+		term.Print("x")
+		term.Refresh()
+		return wantErr
+	})
+	if err != wantErr {
+		t.Errorf("got error %v", err)
+	}
+	if raw || term.InAltScreen() || term.GetMouseMode() != MouseOff {
+		t.Error("terminal not restored")
+	}
+	got := out.String()
+	if !strings.HasSuffix(got, "\033[?25h\033[?1000l\033[?1006l\033[?1049l") {
+		t.Errorf("restore sequence: %q", got)
+	}
+}
+
+func TestFullScreenRestoresOnPanic(t *testing.T) {
+	var out bytes.Buffer
+	term := New(&out)
+	raw := false
+	in := rawReader{strings.NewReader(""), &raw}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("panic was swallowed")
+			}
+		}()
+		term.FullScreen(in, func() error { panic("boom") })
+	}()
+	if raw || term.InAltScreen() {
+		t.Error("terminal not restored after panic")
+	}
+}
+
+func TestFullScreenNotTerminal(t *testing.T) {
+	term := New(&bytes.Buffer{})
+	called := false
+	err := term.FullScreen(strings.NewReader(""), func() error { called = true; return nil })
+	if err != ErrNotTerminal || called {
+		t.Errorf("got %v, called %v", err, called)
+	}
+}
@@ -0,0 +1,319 @@
+// caps.go — detecting what the terminal can display and report.
+// Copyright (C) 2025 R. S. Doiel
+package termlib
+
+import (
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+/** Capabilities describes the features of a terminal beyond basic cursor
+ * control. Terminal consults them so that it does not send sequences the
+ * terminal would show as garbage: unsupported colors are converted,
+ * unsupported attributes are dropped, EnableMouse does nothing without
+ * mouse support and the widgets fall back to ASCII.
+ *
+ * New fills them in from the environment with DetectCapabilities;
+ * Terminal.Probe asks the terminal itself.
+ *
+ * Example:
+ *   if term.Capabilities().Hyperlinks {
+ *       fmt.Print("\033]8;;https://example.org\033\\docs\033]8;;\033\\")
+ *   }
+ */
+type Capabilities struct {
+	Name            string       // terminal name and version from XTVERSION, or $TERM
+	Colors          ColorProfile // color depth; ProfileNoColor when NO_COLOR is set
+	Italic          bool         // italic text (SGR 3)
+	StyledUnderline bool         // curly, dotted and dashed underlines and underline colors
+	CursorStyle     bool         // cursor shape and color (SetCursorShape, SetCursorColor)
+	SyncOutput      bool         // synchronized output (DEC mode 2026)
+	Mouse           bool         // mouse reporting (EnableMouse)
+	KittyKeyboard   bool         // the kitty keyboard protocol (EnableKittyKeyboard)
+	Hyperlinks      bool         // OSC 8 hyperlinks
+	Unicode         bool         // box drawing and block characters
+	Background      Color        // background color reported by OSC 11; DefaultColor if unknown
+}
+
+/** DetectCapabilities works out the terminal's capabilities from the
+ * environment without sending it anything. It combines TERM with the
+ * terminal's terminfo entry, when one is installed, and with a built-in
+ * table of terminal families; COLORTERM and the variables set by popular
+ * terminal emulators; NO_COLOR (https://no-color.org), which turns off
+ * color; and the locale, which decides whether box drawing characters are
+ * used. Unknown terminals are assumed to understand what xterm does.
+ *
+ * Returns:
+ *   Capabilities — the detected capabilities.
+ *
+ * Example:
+ *   caps := termlib.DetectCapabilities()
+ *   if caps.Colors == termlib.ProfileTrueColor { ... }
+ */
+func DetectCapabilities() Capabilities {
+	name := os.Getenv("TERM")
+	caps := familyCapabilities(name)
+	caps.Name = name
+	if ti, err := loadTerminfo(name); err == nil {
+		caps.applyTerminfo(ti)
+	}
+	if p := DetectColorProfile(); p > caps.Colors && caps.Colors != ProfileNoColor {
+		caps.Colors = p
+	}
+	for _, v := range []string{os.Getenv("TERM_PROGRAM"), name} {
+		caps.applyKnown(v)
+	}
+	if v, err := strconv.Atoi(os.Getenv("VTE_VERSION")); err == nil && v >= 5000 {
+		caps.Colors, caps.Hyperlinks = ProfileTrueColor, true
+	}
+	if os.Getenv("WT_SESSION") != "" { // Windows Terminal
+		caps.Colors, caps.Hyperlinks = ProfileTrueColor, true
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		caps.Colors = ProfileNoColor
+	}
+	caps.Unicode = caps.Unicode && localeIsUTF8()
+	return caps
+}
+
+// limitedTerminal reports whether name is a terminal type known to lack
+// the features Terminal otherwise assumes, such as the Linux and BSD
+// consoles and hardware terminals. Such terminals are not sent queries.
+func limitedTerminal(name string) bool {
+	if isHardwareVT(name) {
+		return true
+	}
+	for _, prefix := range []string{"linux", "dumb", "cons", "ansi", "sun", "wsvt", "pcvt"} {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// isHardwareVT reports whether name is a DEC terminal such as vt100 or
+// vt220, as opposed to an emulator such as vte.
+func isHardwareVT(name string) bool {
+	return len(name) > 2 && strings.HasPrefix(name, "vt") && name[2] >= '0' && name[2] <= '9'
+}
+
+// familyCapabilities returns the capabilities assumed for the terminal
+// type name before its terminfo entry and the environment are consulted.
+func familyCapabilities(name string) Capabilities {
+	caps := Capabilities{
+		Colors:          Profile16,
+		Italic:          true,
+		StyledUnderline: true,
+		CursorStyle:     true,
+		Mouse:           true,
+		Unicode:         true,
+	}
+	switch {
+	case name == "dumb":
+		return Capabilities{Colors: ProfileNoColor}
+	case isHardwareVT(name):
+		// Hardware terminals have no colors, and vt220 and later have
+		// the DEC line drawing set rather than Unicode.
+		return Capabilities{Colors: ProfileNoColor}
+	case strings.HasPrefix(name, "screen"):
+		// GNU screen passes mouse reports through but not the newer
+		// attributes.
+		caps.Italic, caps.StyledUnderline, caps.CursorStyle = false, false, false
+	case limitedTerminal(name):
+		caps = Capabilities{Colors: Profile16, Unicode: true}
+	}
+	return caps
+}
+
+// applyTerminfo refines c with what the terminfo entry ti says about the
+// terminal.
+func (c *Capabilities) applyTerminfo(ti *terminfo) {
+	switch n := ti.num(tiMaxColors); {
+	case ti.has("RGB") || ti.has("Tc") || n >= 1<<24:
+		c.Colors = ProfileTrueColor
+	case n >= 88:
+		c.Colors = Profile256
+	case n >= 8:
+		c.Colors = Profile16
+	default:
+		c.Colors = ProfileNoColor
+	}
+	c.Italic = ti.str(tiEnterItalicsMode) != ""
+	c.Mouse = ti.str(tiKeyMouse) != ""
+	if ti.has("Smulx") {
+		c.StyledUnderline = true
+	}
+	if ti.has("Ss") {
+		c.CursorStyle = true
+	}
+	if ti.has("Sync") {
+		c.SyncOutput = true
+	}
+}
+
+// knownTerminals lists terminal emulators known, from their name, to have
+// features that TERM and terminfo usually do not reveal.
+var knownTerminals = []struct {
+	name               string
+	sync, kitty, links bool
+}{
+	{"kitty", true, true, true},
+	{"ghostty", true, true, true},
+	{"wezterm", true, true, true},
+	{"foot", true, true, true},
+	{"alacritty", true, true, true},
+	{"contour", true, false, true},
+	{"iterm", true, false, true},
+	{"vscode", false, false, true},
+}
+
+// applyKnown adds the features of the terminal emulator called name, as
+// reported by TERM, TERM_PROGRAM or XTVERSION, if it is a known one.
+func (c *Capabilities) applyKnown(name string) {
+	name = strings.ToLower(name)
+	for _, k := range knownTerminals {
+		if !strings.Contains(name, k.name) {
+			continue
+		}
+		c.Colors = ProfileTrueColor
+		c.Italic, c.StyledUnderline, c.CursorStyle, c.Mouse = true, true, true, true
+		c.SyncOutput = c.SyncOutput || k.sync
+		c.KittyKeyboard = c.KittyKeyboard || k.kitty
+		c.Hyperlinks = c.Hyperlinks || k.links
+		return
+	}
+}
+
+// localeIsUTF8 reports whether the locale uses UTF-8, following the
+// precedence of LC_ALL, LC_CTYPE and LANG. With no locale set at all, as
+// on Windows, UTF-8 is assumed.
+func localeIsUTF8() bool {
+	for _, v := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if loc := os.Getenv(v); loc != "" {
+			loc = strings.ToLower(loc)
+			return strings.Contains(loc, "utf-8") || strings.Contains(loc, "utf8")
+		}
+	}
+	return true
+}
+
+/** Probe asks the terminal about its features and returns the result,
+ * which the Terminal also keeps. It sends the DA1, DECRQM (for
+ * synchronized output), XTVERSION, OSC 11 (background color) and kitty
+ * keyboard queries together and waits briefly for the replies; a feature
+ * the terminal answers for overrides what DetectCapabilities guessed. If
+ * the terminal does not answer at all, the guesses are kept. Terminals
+ * known not to understand these queries, such as the Linux console, are
+ * not sent them.
+ *
+ * in must be in raw mode and must not be read by anything else, such as a
+ * KeyReader, until Probe returns. Input typed meanwhile is kept for the
+ * next read.
+ *
+ * Parameters:
+ *   in (io.Reader) — terminal input in raw mode, typically os.Stdin.
+ *
+ * Returns:
+ *   Capabilities — the terminal's capabilities.
+ *
+ * Example:
+ *   restore, _ := termlib.EnterRawMode(os.Stdin)
+ *   defer restore()
+ *   if term.Probe(os.Stdin).SyncOutput { ... }
+ */
+func (t *Terminal) Probe(in io.Reader) Capabilities {
+	caps := t.Capabilities()
+	if limitedTerminal(caps.Name) || t.GetOutputMode() == OutputLines || t.Err() != nil {
+		return caps
+	}
+	// DA1 goes last: every terminal answers it, so once its reply has
+	// come, any query without a reply is unsupported.
+	t.writeControl("\033[?2026$p\033[?u\033[>0q\033]11;?\033\\\033[c")
+	var sync, kitty bool
+	dec := takeDecoder(in, true)
+	defer dec.put()
+	answered := dec.awaitReply(queryTimeout, func(reply string) bool {
+		switch {
+		case strings.HasPrefix(reply, "[?2026;") && strings.HasSuffix(reply, "$y"):
+			// Mode 2026 is settable (1, 2) or permanently set (3).
+			st := strings.TrimSuffix(strings.TrimPrefix(reply, "[?2026;"), "$y")
+			sync = st == "1" || st == "2" || st == "3"
+		case strings.HasPrefix(reply, "[?") && strings.HasSuffix(reply, "u"):
+			kitty = true
+		case strings.HasPrefix(reply, "P>|"):
+			caps.Name = reply[3:]
+			caps.applyKnown(caps.Name)
+		case strings.HasPrefix(reply, "]11;"):
+			if c, ok := parseOSCColor(reply[4:]); ok {
+				caps.Background = c
+			}
+		case strings.HasPrefix(reply, "[?") && strings.HasSuffix(reply, "c"):
+			return true
+		}
+		return false
+	})
+	if answered {
+		caps.SyncOutput, caps.KittyKeyboard = sync, kitty
+	}
+	t.SetCapabilities(caps)
+	return caps
+}
+
+// parseOSCColor parses a color in the X11 form "rgb:rrrr/gggg/bbbb" used
+// by OSC color replies, where each component has one to four hex digits.
+func parseOSCColor(s string) (Color, bool) {
+	parts := strings.Split(strings.TrimPrefix(s, "rgb:"), "/")
+	if len(parts) != 3 || !strings.HasPrefix(s, "rgb:") {
+		return DefaultColor, false
+	}
+	var rgb [3]uint8
+	for i, p := range parts {
+		v, err := strconv.ParseUint(p, 16, 16)
+		if err != nil || len(p) == 0 || len(p) > 4 {
+			return DefaultColor, false
+		}
+		max := uint64(1)<<(4*len(p)) - 1
+		rgb[i] = uint8(v * 255 / max)
+	}
+	return RGBColor(rgb[0], rgb[1], rgb[2]), true
+}
+
+// Capabilities returns the capabilities the Terminal is rendering for.
+func (t *Terminal) Capabilities() Capabilities {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.caps
+}
+
+/** SetCapabilities overrides the capabilities detected by New, for
+ * example from a configuration setting or for a remote terminal whose
+ * environment is known. The next Refresh repaints the screen for them.
+ *
+ * Parameters:
+ *   c (Capabilities) — the capabilities to render for.
+ *
+ * Example:
+ *   caps := term.Capabilities()
+ *   caps.Italic = false
+ *   term.SetCapabilities(caps)
+ */
+func (t *Terminal) SetCapabilities(c Capabilities) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.setCapabilities(c)
+}
+
+// setCapabilities changes the capabilities, repainting on the next
+// Refresh if anything drawn may now look different. t.mu must be held.
+func (t *Terminal) setCapabilities(c Capabilities) {
+	if c == t.caps {
+		return
+	}
+	t.caps = c
+	t.render.caps = c
+	if t.drawn {
+		t.invalidate()
+	}
+}
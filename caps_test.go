@@ -0,0 +1,207 @@
+// caps_test.go — tests for terminal capability detection.
+// Copyright (C) 2025 R. S. Doiel
+package termlib
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setTermEnv sets TERM and the other variables DetectCapabilities reads,
+// clearing those not given.
+func setTermEnv(t *testing.T, term string, vars map[string]string) {
+	t.Helper()
+	t.Setenv("TERMINFO", t.TempDir())
+	t.Setenv("TERMINFO_DIRS", "")
+	t.Setenv("TERM", term)
+	for _, v := range []string{"COLORTERM", "TERM_PROGRAM", "VTE_VERSION", "WT_SESSION", "NO_COLOR", "LC_ALL", "LC_CTYPE", "LANG"} {
+		t.Setenv(v, vars[v])
+	}
+}
+
+// writeTerminfo installs a compiled entry for e under $TERMINFO.
+func writeTerminfo(t *testing.T, name string, e tiEntry) {
+	t.Helper()
+	dir := filepath.Join(os.Getenv("TERMINFO"), name[:1])
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), compileTerminfo(e, false), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDetectCapabilities(t *testing.T) {
+	tests := []struct {
+		term string
+		vars map[string]string
+		want Capabilities
+	}{
+		{"xtest-unknown", nil, Capabilities{Name: "xtest-unknown", Colors: Profile16, Italic: true,
+			StyledUnderline: true, CursorStyle: true, Mouse: true, Unicode: true}},
+		{"xtest-256color", map[string]string{"COLORTERM": "truecolor", "LANG": "C"}, Capabilities{Name: "xtest-256color",
+			Colors: ProfileTrueColor, Italic: true, StyledUnderline: true, CursorStyle: true, Mouse: true}},
+		{"xterm-kitty", map[string]string{"LC_ALL": "en_US.UTF-8", "LANG": "C"}, Capabilities{Name: "xterm-kitty",
+			Colors: ProfileTrueColor, Italic: true, StyledUnderline: true, CursorStyle: true, SyncOutput: true,
+			Mouse: true, KittyKeyboard: true, Hyperlinks: true, Unicode: true}},
+		{"xterm", map[string]string{"TERM_PROGRAM": "WezTerm", "NO_COLOR": "1"}, Capabilities{Name: "xterm",
+			Colors: ProfileNoColor, Italic: true, StyledUnderline: true, CursorStyle: true, SyncOutput: true,
+			Mouse: true, KittyKeyboard: true, Hyperlinks: true, Unicode: true}},
+		{"dumb", nil, Capabilities{Name: "dumb", Colors: ProfileNoColor}},
+		{"vt220", nil, Capabilities{Name: "vt220", Colors: ProfileNoColor}},
+		{"vte-256color", nil, Capabilities{Name: "vte-256color", Colors: Profile256, Italic: true,
+			StyledUnderline: true, CursorStyle: true, Mouse: true, Unicode: true}},
+		{"screen", nil, Capabilities{Name: "screen", Colors: Profile16, Mouse: true, Unicode: true}},
+	}
+	for _, tt := range tests {
+		setTermEnv(t, tt.term, tt.vars)
+		if got := DetectCapabilities(); got != tt.want {
+			t.Errorf("TERM=%s %v:\n got %+v\nwant %+v", tt.term, tt.vars, got, tt.want)
+		}
+	}
+}
+
+func TestDetectCapabilitiesTerminfo(t *testing.T) {
+	setTermEnv(t, "xtest-console", nil)
+	writeTerminfo(t, "xtest-console", tiEntry{names: "xtest-console", nums: map[int]int{tiMaxColors: 8}})
+	got := DetectCapabilities()
+	if got.Colors != Profile16 || got.Italic || got.Mouse || got.StyledUnderline != true {
+		t.Errorf("xtest-console: %+v", got)
+	}
+
+	setTermEnv(t, "xtest-direct", nil)
+	writeTerminfo(t, "xtest-direct", tiEntry{
+		names:   "xtest-direct",
+		nums:    map[int]int{tiMaxColors: 8},
+		strs:    map[int]string{tiEnterItalicsMode: "\033[3m", tiKeyMouse: "\033[<"},
+		extBool: []string{"RGB"},
+		extStr:  [][2]string{{"Sync", "\033P=%p1%ds\033\\"}},
+	})
+	got = DetectCapabilities()
+	if got.Colors != ProfileTrueColor || !got.Italic || !got.Mouse || !got.SyncOutput {
+		t.Errorf("xtest-direct: %+v", got)
+	}
+}
+
+func TestRefreshAdaptsToCapabilities(t *testing.T) {
+	var out bytes.Buffer
+	term := New(&out)
+	term.SetSize(10, 1)
+	term.SetCapabilities(Capabilities{Colors: ProfileNoColor})
+	term.PrintStyled(NewStyle().Fg(Red).Bold().Italic().Underline(UnderlineCurly).UnderlineColor(Blue), "x")
+	term.Refresh()
+	if got := out.String(); !strings.Contains(got, "\033[0;1;4mx") {
+		t.Errorf("style not adapted: %q", got)
+	}
+
+	out.Reset()
+	caps := term.Capabilities()
+	caps.Colors, caps.Italic, caps.StyledUnderline = Profile16, true, true
+	term.SetCapabilities(caps)
+	term.Refresh()
+	if got := out.String(); !strings.Contains(got, "31;1;3;4:3;58;5;4mx") {
+		t.Errorf("not repainted for new capabilities: %q", got)
+	}
+}
+
+func TestCapabilitiesGateModes(t *testing.T) {
+	resetRegistry(t)
+	var out bytes.Buffer
+	term := New(&out)
+	term.SetSize(10, 1)
+	term.SetCapabilities(Capabilities{Colors: Profile16})
+	term.EnableMouse(MouseClicks)
+	term.SetCursorShape(CursorSteadyBar)
+	term.Refresh()
+	if got := out.String(); strings.Contains(got, "\033[?1000h") || strings.Contains(got, " q") {
+		t.Errorf("unsupported modes sent: %q", got)
+	}
+	if term.GetMouseMode() != MouseOff || registered() != 0 {
+		t.Error("unsupported modes recorded")
+	}
+}
+
+func TestWidgetsASCII(t *testing.T) {
+	term := New(&bytes.Buffer{})
+	term.SetSize(12, 4)
+	term.SetCapabilities(Capabilities{Colors: Profile16})
+	DrawBox(term, 1, 1, 6, 3, "")
+	DrawProgressBar(term, 4, 1, 6, 1, 2)
+	want := []string{"+----+", "|    |", "+----+", "[##..]"}
+	for i, w := range want {
+		var got strings.Builder
+		for col := 1; col <= len(w); col++ {
+			got.WriteString(term.back.at(i+1, col).ch)
+		}
+		if got.String() != w {
+			t.Errorf("row %d: got %q, want %q", i+1, got.String(), w)
+		}
+	}
+}
+
+func TestProbe(t *testing.T) {
+	var out bytes.Buffer
+	term := New(&out)
+	term.SetCapabilities(Capabilities{Name: "xterm", Colors: Profile256})
+	in := pipeInput(t, "\033[?2026;2$y\033[?1u\033P>|kitty(0.31.0)\033\\\033]11;rgb:0000/8080/ffff\033\\a\033[?62;22c")
+	caps := term.Probe(in)
+	if got := out.String(); got != "\033[?2026$p\033[?u\033[>0q\033]11;?\033\\\033[c" {
+		t.Errorf("queries: %q", got)
+	}
+	if !caps.SyncOutput || !caps.KittyKeyboard || caps.Name != "kitty(0.31.0)" ||
+		caps.Background != RGBColor(0, 0x80, 0xff) || !caps.Hyperlinks {
+		t.Errorf("got %+v", caps)
+	}
+	if term.Capabilities() != caps {
+		t.Error("capabilities not kept")
+	}
+	if k, err := ReadKey(in); k != Key('a') || err != nil {
+		t.Errorf("typed key lost: %v, %v", k, err)
+	}
+}
+
+func TestProbeUnanswered(t *testing.T) {
+	var out bytes.Buffer
+	term := New(&out)
+
+	// Only DA1 answered: the other features are missing.
+	term.SetCapabilities(Capabilities{Name: "xterm", SyncOutput: true, KittyKeyboard: true})
+	if caps := term.Probe(pipeInput(t, "\033[?1;2c")); caps.SyncOutput || caps.KittyKeyboard {
+		t.Errorf("got %+v", caps)
+	}
+
+	// Mode 2026 not recognised.
+	term.SetCapabilities(Capabilities{Name: "xterm"})
+	if caps := term.Probe(pipeInput(t, "\033[?2026;0$y\033[?1;2c")); caps.SyncOutput {
+		t.Errorf("got %+v", caps)
+	}
+
+	// The Linux console is not queried.
+	out.Reset()
+	term.SetCapabilities(Capabilities{Name: "linux", Colors: Profile16})
+	term.Probe(pipeInput(t, ""))
+	if out.Len() != 0 {
+		t.Errorf("queries sent to the Linux console: %q", out.String())
+	}
+}
+
+func TestParseOSCColor(t *testing.T) {
+	tests := []struct {
+		s    string
+		want Color
+		ok   bool
+	}{
+		{"rgb:ffff/0000/8080", RGBColor(0xff, 0, 0x80), true},
+		{"rgb:f/0/8", RGBColor(0xff, 0, 0x88), true},
+		{"rgb:ff/00", DefaultColor, false},
+		{"#ff0000", DefaultColor, false},
+	}
+	for _, tt := range tests {
+		if got, ok := parseOSCColor(tt.s); got != tt.want || ok != tt.ok {
+			t.Errorf("parseOSCColor(%q) = %v, %v", tt.s, got, ok)
+		}
+	}
+}
@@ -11,6 +11,8 @@ import (
 )
 
 func main() {
+	defer termlib.RestoreOnPanic()
+	termlib.SetRestoreSignals(termlib.TerminationSignals...)
 	appName := filepath.Base(os.Args[0])
 	helpText, fmtHelp := termlib.DemoHelpText, termlib.FmtHelp
 	version, releaseDate, releaseHash, licenseText := termlib.Version, termlib.ReleaseDate, termlib.ReleaseHash, termlib.LicenseText
@@ -37,7 +39,42 @@ func main() {
 		os.Exit(0)
 	}
 
+	// Run the demo on the alternate screen so that the user's scrollback
+	// is left untouched. When stdin is not a terminal, draw in place.
 	term := termlib.New(out)
+	completed := false
+	err := term.FullScreen(os.Stdin, func() error {
+		// Ask the terminal what it supports, such as synchronized output,
+		// before anything else reads the keyboard.
+		term.Probe(os.Stdin)
+		// Raw mode turns Ctrl+C into an ordinary key, so watch for it.
+		quit := make(chan struct{})
+		keys, stopKeys := termlib.KeyReaderStop(os.Stdin)
+		defer stopKeys()
+		go func() {
+			for k := range keys {
+				if k == termlib.Key(0x03) || k == termlib.Key('q') {
+					close(quit)
+					return
+				}
+			}
+		}()
+		completed = runDemo(term, quit)
+		return nil
+	})
+	if err != nil {
+		runDemo(term, nil)
+		return
+	}
+	if completed {
+		fmt.Fprintln(out, "Task completed!")
+	}
+}
+
+// runDemo clears the screen and steps through a simulated task, showing
+// the color and style demo part of the way through. It stops early, and
+// reports false, when quit is closed.
+func runDemo(term *termlib.Terminal, quit <-chan struct{}) bool {
 	term.Clear()
 
 	// Simulate a task with 10 steps
@@ -57,18 +94,24 @@ func main() {
 		if i == 3 && !demoShown {
 			showStyleDemo(term)
 			demoShown = true
-			time.Sleep(2 * time.Second) // Give time to view the demo
+			if !pause(2*time.Second, quit) { // Give time to view the demo
+				return false
+			}
 		}
 
 		// Simulate work
-		time.Sleep(500 * time.Millisecond)
+		if !pause(500*time.Millisecond, quit) {
+			return false
+		}
 
 		// Update progress counter at the bottom
 		term.Move(term.GetTerminalHeight(), 1)
 		term.ClrToEOL()
 		progress := float64(i+1) / float64(totalSteps) * 100
 		term.Print(fmt.Sprintf("Progress: %d%%", int(progress)))
-		term.Refresh()
+		if term.Refresh() != nil {
+			return false // the output has gone away
+		}
 	}
 
 	// Final message
@@ -76,6 +119,17 @@ func main() {
 	term.ClrToEOL()
 	term.Print("Task completed!")
 	term.Refresh()
+	return true
+}
+
+// pause waits for d, returning false if quit is closed first.
+func pause(d time.Duration, quit <-chan struct{}) bool {
+	select {
+	case <-time.After(d):
+		return true
+	case <-quit:
+		return false
+	}
 }
 
 func showStyleDemo(term *termlib.Terminal) {
@@ -97,37 +151,26 @@ func showStyleDemo(term *termlib.Terminal) {
 	term.Print("Foreground Colors:")
 	term.ResetStyle()
 
-	term.Move(6, 3)
-	term.SetFgColor(termlib.Black)
-	term.Print("■ Black")
-
-	term.Move(7, 3)
-	term.SetFgColor(termlib.Red)
-	term.Print("■ Red")
-
-	term.Move(8, 3)
-	term.SetFgColor(termlib.Green)
-	term.Print("■ Green")
-
-	term.Move(9, 3)
-	term.SetFgColor(termlib.Yellow)
-	term.Print("■ Yellow")
-
-	term.Move(10, 3)
-	term.SetFgColor(termlib.Blue)
-	term.Print("■ Blue")
-
-	term.Move(11, 3)
-	term.SetFgColor(termlib.Magenta)
-	term.Print("■ Magenta")
-
-	term.Move(12, 3)
-	term.SetFgColor(termlib.Cyan)
-	term.Print("■ Cyan")
-
-	term.Move(13, 3)
-	term.SetFgColor(termlib.White)
-	term.Print("■ White")
+	colors := []struct {
+		name  string
+		color termlib.Color
+	}{
+		{"Black", termlib.Black},
+		{"Red", termlib.Red},
+		{"Green", termlib.Green},
+		{"Yellow", termlib.Yellow},
+		{"Blue", termlib.Blue},
+		{"Magenta", termlib.Magenta},
+		{"Cyan", termlib.Cyan},
+		{"White", termlib.White},
+	}
+	for i, c := range colors {
+		term.Move(6+i, 3)
+		term.PushStyle()
+		term.SetFgColor(c.color)
+		term.Print("■ " + c.name)
+		term.PopStyle()
+	}
 
 	// Background colors section
 	term.Move(5, 25)
@@ -135,37 +178,13 @@ func showStyleDemo(term *termlib.Terminal) {
 	term.Print("Background Colors:")
 	term.ResetStyle()
 
-	term.Move(6, 27)
-	term.SetBgColor(termlib.BlackBg)
-	term.Print(" Black  ")
-
-	term.Move(7, 27)
-	term.SetBgColor(termlib.RedBg)
-	term.Print(" Red    ")
-
-	term.Move(8, 27)
-	term.SetBgColor(termlib.GreenBg)
-	term.Print(" Green  ")
-
-	term.Move(9, 27)
-	term.SetBgColor(termlib.YellowBg)
-	term.Print(" Yellow ")
-
-	term.Move(10, 27)
-	term.SetBgColor(termlib.BlueBg)
-	term.Print(" Blue   ")
-
-	term.Move(11, 27)
-	term.SetBgColor(termlib.MagentaBg)
-	term.Print(" Magenta")
-
-	term.Move(12, 27)
-	term.SetBgColor(termlib.CyanBg)
-	term.Print(" Cyan   ")
-
-	term.Move(13, 27)
-	term.SetBgColor(termlib.WhiteBg)
-	term.Print(" White  ")
+	for i, c := range colors {
+		term.Move(6+i, 27)
+		term.PushStyle()
+		term.SetBgColor(c.color)
+		term.Print(" " + termlib.PadRight(c.name, 7))
+		term.PopStyle()
+	}
 
 	// Text styles section
 	term.Move(5, 50)
@@ -174,17 +193,23 @@ func showStyleDemo(term *termlib.Terminal) {
 	term.ResetStyle()
 
 	term.Move(6, 52)
+	term.PushStyle()
 	term.SetBold()
 	term.Print("Bold text")
+	term.PopStyle()
 
 	term.Move(7, 52)
+	term.PushStyle()
 	term.SetItalic()
 	term.Print("Italic text")
+	term.PopStyle()
 
 	term.Move(8, 52)
+	term.PushStyle()
 	term.SetBold()
 	term.SetItalic()
 	term.Print("Bold Italic")
+	term.PopStyle()
 
 	// Combined styles section
 	term.Move(10, 50)
@@ -193,20 +218,26 @@ func showStyleDemo(term *termlib.Terminal) {
 	term.ResetStyle()
 
 	term.Move(11, 52)
+	term.PushStyle()
 	term.SetFgColor(termlib.White)
 	term.SetBgColor(termlib.BlueBg)
 	term.Print(" White on Blue ")
+	term.PopStyle()
 
 	term.Move(12, 52)
+	term.PushStyle()
 	term.SetFgColor(termlib.Black)
 	term.SetBgColor(termlib.YellowBg)
 	term.Print(" Black on Yellow ")
+	term.PopStyle()
 
 	term.Move(13, 52)
+	term.PushStyle()
 	term.SetFgColor(termlib.Red)
 	term.SetBgColor(termlib.WhiteBg)
 	term.SetBold()
 	term.Print(" Red Bold on White ")
+	term.PopStyle()
 
 	// Reset all styles
 	term.ResetStyle()
@@ -0,0 +1,336 @@
+// color.go — typed terminal colors and color depth down-conversion.
+// Copyright (C) 2025 R. S. Doiel
+package termlib
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+/** Color is a terminal color. It is either the terminal's default color,
+ * an entry in the xterm 256-color palette (entries 0–15 are the 16 ANSI
+ * colors), or a 24-bit RGB value. The zero value is DefaultColor.
+ *
+ * Colors are stored exactly as given; when a Terminal renders them on a
+ * terminal with fewer colors they are converted to the nearest color
+ * that terminal supports (see ColorProfile).
+ *
+ * Example:
+ *   accent, _ := termlib.HexColor("#ff8700")
+ *   term.SetFgColor(accent)
+ *   term.SetBgColor(termlib.PaletteColor(236))
+ */
+type Color uint32
+
+const (
+	colorPalette Color = 1 << 24 // low byte is a palette index
+	colorRGB     Color = 2 << 24 // low 24 bits are 0xRRGGBB
+	colorKind    Color = 0xff << 24
+)
+
+// DefaultColor is the terminal's own foreground or background color.
+const DefaultColor Color = 0
+
+// The 16 ANSI colors, entries 0–15 of the palette.
+const (
+	Black Color = colorPalette + iota
+	Red
+	Green
+	Yellow
+	Blue
+	Magenta
+	Cyan
+	White
+	BrightBlack
+	BrightRed
+	BrightGreen
+	BrightYellow
+	BrightBlue
+	BrightMagenta
+	BrightCyan
+	BrightWhite
+)
+
+// Background aliases kept so existing SetBgColor(RedBg) calls still read
+// naturally. They are the same values as the foreground constants.
+const (
+	BlackBg   = Black
+	RedBg     = Red
+	GreenBg   = Green
+	YellowBg  = Yellow
+	BlueBg    = Blue
+	MagentaBg = Magenta
+	CyanBg    = Cyan
+	WhiteBg   = White
+)
+
+/** PaletteColor returns entry n of the xterm 256-color palette.
+ *
+ * Parameters:
+ *   n (uint8) — palette index; 0–15 ANSI colors, 16–231 the 6×6×6 color
+ *               cube, 232–255 the grayscale ramp.
+ *
+ * Returns:
+ *   Color — the palette color.
+ *
+ * Example:
+ *   orange := termlib.PaletteColor(208)
+ */
+func PaletteColor(n uint8) Color {
+	return colorPalette | Color(n)
+}
+
+/** RGBColor returns a 24-bit color.
+ *
+ * Parameters:
+ *   r, g, b (uint8) — red, green and blue components.
+ *
+ * Returns:
+ *   Color — the RGB color.
+ *
+ * Example:
+ *   teal := termlib.RGBColor(0, 128, 128)
+ */
+func RGBColor(r, g, b uint8) Color {
+	return colorRGB | Color(r)<<16 | Color(g)<<8 | Color(b)
+}
+
+/** HexColor parses a CSS-style hex color: "#rrggbb" or "#rgb", with the
+ * leading "#" optional.
+ *
+ * Parameters:
+ *   s (string) — the hex color.
+ *
+ * Returns:
+ *   Color — the RGB color.
+ *   error — non-nil if s is not a valid hex color.
+ *
+ * Example:
+ *   bg, err := termlib.HexColor("#1e1e2e")
+ */
+func HexColor(s string) (Color, error) {
+	h := strings.TrimPrefix(s, "#")
+	if len(h) == 3 {
+		h = string([]byte{h[0], h[0], h[1], h[1], h[2], h[2]})
+	}
+	if len(h) != 6 {
+		return DefaultColor, fmt.Errorf("invalid hex color %q", s)
+	}
+	v, err := strconv.ParseUint(h, 16, 32)
+	if err != nil {
+		return DefaultColor, fmt.Errorf("invalid hex color %q", s)
+	}
+	return colorRGB | Color(v), nil
+}
+
+// IsDefault reports whether c is the terminal's default color.
+func (c Color) IsDefault() bool {
+	return c&colorKind == 0
+}
+
+/** RGB returns the red, green and blue components of c. Palette colors
+ * use the standard xterm palette values. DefaultColor reports black.
+ *
+ * Returns:
+ *   r, g, b (uint8) — color components.
+ */
+func (c Color) RGB() (r, g, b uint8) {
+	switch c & colorKind {
+	case colorRGB:
+		return uint8(c >> 16), uint8(c >> 8), uint8(c)
+	case colorPalette:
+		v := paletteRGB(uint8(c))
+		return uint8(v >> 16), uint8(v >> 8), uint8(v)
+	}
+	return 0, 0, 0
+}
+
+// String returns a readable name for c, e.g. "default", "palette(208)" or
+// "#ff8700".
+func (c Color) String() string {
+	switch c & colorKind {
+	case colorRGB:
+		return fmt.Sprintf("#%06x", uint32(c&0xffffff))
+	case colorPalette:
+		return fmt.Sprintf("palette(%d)", uint8(c))
+	}
+	return "default"
+}
+
+/** ColorProfile describes how many colors a terminal can display.
+ * Colors richer than the profile are converted to the nearest color the
+ * profile supports when rendered.
+ */
+type ColorProfile int
+
+const (
+	ProfileNoColor   ColorProfile = iota // no colors, only attributes such as bold
+	Profile16                            // the 16 ANSI colors
+	Profile256                           // the xterm 256-color palette
+	ProfileTrueColor                     // 24-bit RGB
+)
+
+/** DetectColorProfile inspects the environment to decide how many colors
+ * the terminal supports. COLORTERM=truecolor (or 24bit) and TERM values
+ * ending in "-direct" indicate 24-bit color; a TERM containing "256color"
+ * indicates the 256-color palette; anything else is treated as 16 colors.
+ *
+ * Returns:
+ *   ColorProfile — the detected profile.
+ *
+ * Example:
+ *   if termlib.DetectColorProfile() == termlib.ProfileTrueColor { ... }
+ */
+func DetectColorProfile() ColorProfile {
+	switch strings.ToLower(os.Getenv("COLORTERM")) {
+	case "truecolor", "24bit":
+		return ProfileTrueColor
+	}
+	t := os.Getenv("TERM")
+	switch {
+	case strings.HasSuffix(t, "-direct"):
+		return ProfileTrueColor
+	case strings.Contains(t, "256color"):
+		return Profile256
+	}
+	return Profile16
+}
+
+// convert returns the nearest color to c that profile p can display.
+func (c Color) convert(p ColorProfile) Color {
+	if p == ProfileNoColor {
+		return DefaultColor
+	}
+	switch c & colorKind {
+	case colorRGB:
+		switch p {
+		case Profile256:
+			return PaletteColor(nearestPalette(c))
+		case Profile16:
+			return PaletteColor(nearestANSI(c))
+		}
+	case colorPalette:
+		if p == Profile16 && uint8(c) >= 16 {
+			return PaletteColor(nearestANSI(c))
+		}
+	}
+	return c
+}
+
+// sgr returns the SGR parameters selecting c as the foreground color, or
+// as the background color when bg is true.
+func (c Color) sgr(bg bool) string {
+	base := 30
+	if bg {
+		base = 40
+	}
+	switch c & colorKind {
+	case colorRGB:
+		return fmt.Sprintf("%d;2;%d;%d;%d", base+8, uint8(c>>16), uint8(c>>8), uint8(c))
+	case colorPalette:
+		n := int(uint8(c))
+		switch {
+		case n < 8:
+			return strconv.Itoa(base + n)
+		case n < 16:
+			return strconv.Itoa(base + 60 + n - 8)
+		}
+		return fmt.Sprintf("%d;5;%d", base+8, n)
+	}
+	return strconv.Itoa(base + 9)
+}
+
+// sgrUnderline returns the SGR parameters selecting c as the underline
+// color. Unlike 38 and 48 there is no short form for the 16 ANSI colors.
+func (c Color) sgrUnderline() string {
+	switch c & colorKind {
+	case colorRGB:
+		return fmt.Sprintf("58;2;%d;%d;%d", uint8(c>>16), uint8(c>>8), uint8(c))
+	case colorPalette:
+		return fmt.Sprintf("58;5;%d", uint8(c))
+	}
+	return "59"
+}
+
+// ansiRGB holds the xterm default values for palette entries 0–15.
+var ansiRGB = [16]uint32{
+	0x000000, 0xcd0000, 0x00cd00, 0xcdcd00, 0x0000ee, 0xcd00cd, 0x00cdcd, 0xe5e5e5,
+	0x7f7f7f, 0xff0000, 0x00ff00, 0xffff00, 0x5c5cff, 0xff00ff, 0x00ffff, 0xffffff,
+}
+
+// cubeLevels are the component values used by the 6×6×6 color cube.
+var cubeLevels = [6]int{0, 95, 135, 175, 215, 255}
+
+// paletteRGB returns the 0xRRGGBB value of palette entry n.
+func paletteRGB(n uint8) uint32 {
+	switch {
+	case n < 16:
+		return ansiRGB[n]
+	case n < 232:
+		i := int(n) - 16
+		r, g, b := cubeLevels[i/36], cubeLevels[i/6%6], cubeLevels[i%6]
+		return uint32(r<<16 | g<<8 | b)
+	}
+	v := 8 + 10*(int(n)-232)
+	return uint32(v<<16 | v<<8 | v)
+}
+
+// colorDist returns the squared distance between two RGB colors.
+func colorDist(r1, g1, b1, r2, g2, b2 int) int {
+	dr, dg, db := r1-r2, g1-g2, b1-b2
+	return dr*dr + dg*dg + db*db
+}
+
+// cubeIndex maps a color component to the nearest color cube level.
+func cubeIndex(v int) int {
+	switch {
+	case v < 48:
+		return 0
+	case v < 115:
+		return 1
+	}
+	return (v - 35) / 40
+}
+
+// nearestPalette returns the palette entry in the range 16–255 closest to
+// c, choosing between the best color cube and grayscale ramp entries.
+func nearestPalette(c Color) uint8 {
+	r8, g8, b8 := c.RGB()
+	r, g, b := int(r8), int(g8), int(b8)
+
+	ri, gi, bi := cubeIndex(r), cubeIndex(g), cubeIndex(b)
+	cube := 16 + 36*ri + 6*gi + bi
+	cubeDist := colorDist(r, g, b, cubeLevels[ri], cubeLevels[gi], cubeLevels[bi])
+
+	avg := (r + g + b) / 3
+	grayIdx := 23
+	if avg < 238 {
+		grayIdx = (avg - 3) / 10
+		if grayIdx < 0 {
+			grayIdx = 0
+		}
+	}
+	gv := 8 + 10*grayIdx
+	grayDist := colorDist(r, g, b, gv, gv, gv)
+
+	if grayDist < cubeDist {
+		return uint8(232 + grayIdx)
+	}
+	return uint8(cube)
+}
+
+// nearestANSI returns the index (0–15) of the ANSI color closest to c.
+func nearestANSI(c Color) uint8 {
+	r8, g8, b8 := c.RGB()
+	r, g, b := int(r8), int(g8), int(b8)
+	best, bestDist := 0, -1
+	for i, v := range ansiRGB {
+		d := colorDist(r, g, b, int(v>>16), int(v>>8&0xff), int(v&0xff))
+		if bestDist < 0 || d < bestDist {
+			best, bestDist = i, d
+		}
+	}
+	return uint8(best)
+}
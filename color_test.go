@@ -0,0 +1,121 @@
+// color_test.go — tests for typed colors and color depth conversion.
+// Copyright (C) 2025 R. S. Doiel
+package termlib
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestHexColor(t *testing.T) {
+	cases := []struct {
+		in   string
+		want Color
+		ok   bool
+	}{
+		{"#ff8700", RGBColor(0xff, 0x87, 0x00), true},
+		{"1e1e2e", RGBColor(0x1e, 0x1e, 0x2e), true},
+		{"#abc", RGBColor(0xaa, 0xbb, 0xcc), true},
+		{"#12345", DefaultColor, false},
+		{"#gg0000", DefaultColor, false},
+	}
+	for _, c := range cases {
+		got, err := HexColor(c.in)
+		if (err == nil) != c.ok {
+			t.Errorf("HexColor(%q) error = %v, want ok=%v", c.in, err, c.ok)
+			continue
+		}
+		if got != c.want {
+			t.Errorf("HexColor(%q) = %v, want %v", c.in, got, c.want)
+		}
+	}
+}
+
+func TestColorSGR(t *testing.T) {
+	cases := []struct {
+		c    Color
+		bg   bool
+		want string
+	}{
+		{DefaultColor, false, "39"},
+		{DefaultColor, true, "49"},
+		{Red, false, "31"},
+		{RedBg, true, "41"},
+		{BrightCyan, false, "96"},
+		{BrightWhite, true, "107"},
+		{PaletteColor(208), false, "38;5;208"},
+		{RGBColor(1, 2, 3), true, "48;2;1;2;3"},
+	}
+	for _, c := range cases {
+		if got := c.c.sgr(c.bg); got != c.want {
+			t.Errorf("%v.sgr(%v) = %q, want %q", c.c, c.bg, got, c.want)
+		}
+	}
+}
+
+func TestColorConvert(t *testing.T) {
+	cases := []struct {
+		c    Color
+		p    ColorProfile
+		want Color
+	}{
+		{RGBColor(0xff, 0x87, 0x00), ProfileTrueColor, RGBColor(0xff, 0x87, 0x00)},
+		{RGBColor(0xff, 0x87, 0x00), Profile256, PaletteColor(208)},
+		{RGBColor(0x80, 0x80, 0x80), Profile256, PaletteColor(244)},
+		{RGBColor(0xff, 0x00, 0x00), Profile16, BrightRed},
+		{RGBColor(0x00, 0x00, 0x00), Profile16, Black},
+		{PaletteColor(196), Profile16, BrightRed},
+		{PaletteColor(196), Profile256, PaletteColor(196)},
+		{Blue, Profile16, Blue},
+		{DefaultColor, Profile16, DefaultColor},
+	}
+	for _, c := range cases {
+		if got := c.c.convert(c.p); got != c.want {
+			t.Errorf("%v.convert(%d) = %v, want %v", c.c, c.p, got, c.want)
+		}
+	}
+}
+
+func TestDetectColorProfile(t *testing.T) {
+	cases := []struct {
+		colorterm, term string
+		want            ColorProfile
+	}{
+		{"truecolor", "xterm-256color", ProfileTrueColor},
+		{"24bit", "xterm", ProfileTrueColor},
+		{"", "xterm-direct", ProfileTrueColor},
+		{"", "screen-256color", Profile256},
+		{"", "xterm", Profile16},
+		{"", "linux", Profile16},
+	}
+	for _, c := range cases {
+		t.Setenv("COLORTERM", c.colorterm)
+		t.Setenv("TERM", c.term)
+		if got := DetectColorProfile(); got != c.want {
+			t.Errorf("COLORTERM=%q TERM=%q: got %d, want %d", c.colorterm, c.term, got, c.want)
+		}
+	}
+}
+
+func TestTerminalRGBDownConversion(t *testing.T) {
+	orange := RGBColor(0xff, 0x87, 0x00)
+	cases := []struct {
+		p    ColorProfile
+		want string
+	}{
+		{ProfileTrueColor, "\033[H\033[0;38;2;255;135;0mx\033[0m"},
+		{Profile256, "\033[H\033[0;38;5;208mx\033[0m"},
+		{Profile16, "\033[H\033[0;33mx\033[0m"},
+	}
+	for _, c := range cases {
+		var buf bytes.Buffer
+		term := New(&buf)
+		term.SetColorProfile(c.p)
+		term.SetFgColor(orange)
+		term.Print("x")
+		term.Refresh()
+		if got := buf.String(); got != c.want {
+			t.Errorf("profile %d: got %q, want %q", c.p, got, c.want)
+		}
+	}
+}
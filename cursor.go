@@ -0,0 +1,121 @@
+// cursor.go — cursor shape and cursor color.
+// Copyright (C) 2025 R. S. Doiel
+package termlib
+
+import "fmt"
+
+/** CursorShape is a cursor style set with DECSCUSR. The values match the
+ * DECSCUSR parameter; CursorDefault is the shape the user has configured.
+ *
+ * Example:
+ *   term.SetCursorShape(termlib.CursorSteadyBar) // insert mode
+ *   term.Refresh()
+ */
+type CursorShape int
+
+const (
+	CursorDefault           CursorShape = iota // the terminal's configured shape
+	CursorBlinkingBlock                        // blinking block
+	CursorSteadyBlock                          // steady block
+	CursorBlinkingUnderline                    // blinking underline
+	CursorSteadyUnderline                      // steady underline
+	CursorBlinkingBar                          // blinking vertical bar
+	CursorSteadyBar                            // steady vertical bar
+)
+
+// seq returns the DECSCUSR sequence selecting s.
+func (s CursorShape) seq() string {
+	return fmt.Sprintf("\033[%d q", int(s))
+}
+
+// cursorColorSeq returns the OSC 12 sequence setting the cursor color to
+// c, or OSC 112 resetting it when c is DefaultColor.
+func cursorColorSeq(c Color) string {
+	if c.IsDefault() {
+		return "\033]112\033\\"
+	}
+	r, g, b := c.RGB()
+	return fmt.Sprintf("\033]12;#%02x%02x%02x\033\\", r, g, b)
+}
+
+/** SetCursorShape sets the cursor shape, taking effect at the next Refresh
+ * on terminals with cursor styles (see Capabilities). The terminal's own
+ * shape comes back when the program ends through FullScreen or
+ * RestoreTerminal, and while it is suspended.
+ *
+ * Parameters:
+ *   s (CursorShape) — the shape; CursorDefault restores the user's shape.
+ *
+ * Example:
+ *   term.SetCursorShape(termlib.CursorBlinkingBlock) // normal mode
+ */
+func (t *Terminal) SetCursorShape(s CursorShape) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cursorShape = s
+}
+
+// GetCursorShape returns the cursor shape set by SetCursorShape.
+func (t *Terminal) GetCursorShape() CursorShape {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.cursorShape
+}
+
+/** SetCursorColor sets the color of the cursor with OSC 12, taking effect
+ * at the next Refresh. The terminal's own color comes back when the
+ * program ends through FullScreen or RestoreTerminal, and while it is
+ * suspended. Terminals without OSC 12 ignore it.
+ *
+ * Parameters:
+ *   c (Color) — the cursor color; DefaultColor restores the user's color.
+ *
+ * Example:
+ *   term.SetCursorColor(termlib.RGBColor(0xff, 0x87, 0x00))
+ */
+func (t *Terminal) SetCursorColor(c Color) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cursorColor = c
+}
+
+// GetCursorColor returns the cursor color set by SetCursorColor.
+func (t *Terminal) GetCursorColor() Color {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.cursorColor
+}
+
+// cursorLookSeq returns the sequences bringing the terminal's cursor shape
+// and color up to date, recording them as sent. Nothing is sent to a
+// terminal without cursor styles. t.mu must be held.
+func (t *Terminal) cursorLookSeq() string {
+	if !t.caps.CursorStyle {
+		return ""
+	}
+	var seq string
+	if t.cursorShape != t.termCurShape {
+		seq += t.cursorShape.seq()
+		t.termCurShape = t.cursorShape
+	}
+	if t.cursorColor != t.termCurColor {
+		seq += cursorColorSeq(t.cursorColor)
+		t.termCurColor = t.cursorColor
+	}
+	return seq
+}
+
+// resetCursorLookSeq returns the sequences giving the terminal back its
+// own cursor shape and color, recording them as sent. t.mu must be held.
+func (t *Terminal) resetCursorLookSeq() string {
+	var seq string
+	if t.termCurShape != CursorDefault {
+		seq += CursorDefault.seq()
+		t.termCurShape = CursorDefault
+	}
+	if !t.termCurColor.IsDefault() {
+		seq += cursorColorSeq(DefaultColor)
+		t.termCurColor = DefaultColor
+	}
+	return seq
+}
@@ -0,0 +1,74 @@
+// cursor_test.go — tests for cursor shape and cursor color.
+// Copyright (C) 2025 R. S. Doiel
+package termlib
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestCursorLook(t *testing.T) {
+	resetRegistry(t)
+	var out bytes.Buffer
+	term := New(&out)
+	term.SetSize(5, 1)
+	term.SetCursorShape(CursorSteadyBar)
+	term.SetCursorColor(RGBColor(0xff, 0x87, 0x00))
+	if term.GetCursorShape() != CursorSteadyBar {
+		t.Errorf("GetCursorShape: got %d", term.GetCursorShape())
+	}
+	if out.Len() != 0 {
+		t.Errorf("written before Refresh: %q", out.String())
+	}
+	term.Refresh()
+	got := out.String()
+	if !strings.Contains(got, "\033[6 q") || !strings.Contains(got, "\033]12;#ff8700\033\\") {
+		t.Errorf("cursor look not sent: %q", got)
+	}
+
+	// Unchanged: not sent again.
+	out.Reset()
+	term.Print("x")
+	term.Refresh()
+	if got := out.String(); strings.Contains(got, " q") || strings.Contains(got, "\033]12") {
+		t.Errorf("cursor look sent again: %q", got)
+	}
+
+	out.Reset()
+	RestoreTerminal()
+	if got := out.String(); got != "\033[0 q\033]112\033\\" {
+		t.Errorf("restore: got %q", got)
+	}
+	if registered() != 0 {
+		t.Error("terminal still registered after restore")
+	}
+}
+
+func TestCursorLookSuspend(t *testing.T) {
+	if !jobControl {
+		t.Skip("no job control on this platform")
+	}
+	resetRegistry(t)
+	var out bytes.Buffer
+	term := New(&out)
+	term.SetSize(5, 1)
+	term.SetCursorShape(CursorBlinkingUnderline)
+	term.Refresh()
+	out.Reset()
+
+	defer func(orig func() error) { suspendStop = orig }(suspendStop)
+	suspendStop = func() error {
+		if got := out.String(); got != "\033[0 q" {
+			t.Errorf("not reset before stopping: %q", got)
+		}
+		out.Reset()
+		return nil
+	}
+	if err := term.Suspend(); err != nil {
+		t.Fatal(err)
+	}
+	if got := out.String(); !strings.Contains(got, "\033[3 q") {
+		t.Errorf("shape not sent again: %q", got)
+	}
+}
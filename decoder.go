@@ -0,0 +1,541 @@
+// decoder.go — a stateful decoder turning raw terminal input into events.
+// Copyright (C) 2025 R. S. Doiel
+package termlib
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+/** Decoder turns the bytes a terminal sends into keys and events. It reads
+ * from its source in chunks rather than a byte at a time, keeps any bytes
+ * beyond the current event for the next call, and copes with escape
+ * sequences, UTF-8 characters and pastes split across reads.
+ *
+ * A lone ESC is reported as the Escape key once the escape timeout passes
+ * without more input (see SetEscapeTimeout). For sources with a file
+ * descriptor the wait uses the descriptor directly; for other readers a
+ * goroutine reads ahead into the Decoder, so such a reader should not be
+ * read by anything else once it has been given to a Decoder.
+ *
+ * A Decoder is not safe for concurrent use.
+ *
+ * Example:
+ *   dec := termlib.NewDecoder(conn)
+ *   for {
+ *       ev, err := dec.ReadEvent()
+ *       if err != nil { break }
+ *       handle(ev)
+ *   }
+ */
+type Decoder struct {
+	r        io.Reader
+	fd       uintptr
+	hasFd    bool
+	buf      []byte // bytes read but not yet decoded
+	chunk    []byte
+	err      error // sticky read error, reported once buf is drained
+	pump     chan inputChunk
+	pending  []pendingEvent // decoded while awaiting a reply
+	shared   bool           // a file's Decoder, kept in sharedDecoders
+	byteWise bool           // reads a byte at a time, without a read-ahead goroutine
+	done     chan struct{}  // closed by Close
+
+	// Guarded by sharedDecodersMu.
+	parked     bool // kept in sharedDecoders until taken again
+	readFailed bool // the reader has returned an error
+}
+
+// pendingEvent is an event decoded by awaitReply, kept for next.
+type pendingEvent struct {
+	ev    Event
+	plain bool
+}
+
+// inputChunk is one read made by a Decoder's read-ahead goroutine.
+type inputChunk struct {
+	data []byte
+	err  error
+}
+
+// decoderChunkSize is how many bytes a Decoder asks for per read.
+const decoderChunkSize = 256
+
+/** NewDecoder returns a Decoder reading from r.
+ *
+ * Parameters:
+ *   r (io.Reader) — the terminal input, e.g. os.Stdin in raw mode or a
+ *                   network connection.
+ *
+ * Returns:
+ *   *Decoder — ready to use.
+ *
+ * Example:
+ *   dec := termlib.NewDecoder(os.Stdin)
+ */
+func NewDecoder(r io.Reader) *Decoder {
+	d := &Decoder{r: r, chunk: make([]byte, decoderChunkSize), done: make(chan struct{})}
+	if f, ok := r.(interface{ Fd() uintptr }); ok {
+		d.fd, d.hasFd = f.Fd(), true
+	}
+	return d
+}
+
+var (
+	sharedDecodersMu sync.Mutex
+	sharedDecoders   = map[io.Reader]*Decoder{}
+)
+
+// sharedDecoders holds the Decoder of each *os.File read by ReadKey,
+// ReadEvent, EventReader, Probe or EnableKittyKeyboard, so that bytes read
+// ahead by one are seen by the next. A file's Decoder is forgotten once
+// the file reports an error such as io.EOF.
+//
+// Other readers have no shared Decoder: whoever reads them takes one with
+// takeDecoder and hands it back with put. A Decoder handed back while its
+// read-ahead goroutine still waits in Read is parked in sharedDecoders
+// until the next user takes it, as the input that read brings would
+// otherwise be lost, and is dropped once that read fails.
+
+// takeDecoder returns a Decoder for reading in: a file's shared Decoder,
+// the Decoder parked for another reader, or else a new one. A new one
+// reads ahead only when readAhead is set, which it must be to wait with a
+// timeout or a stop channel on a reader without a file descriptor; one
+// that does not read ahead takes a byte at a time and never needs parking.
+func takeDecoder(in io.Reader, readAhead bool) *Decoder {
+	sharedDecodersMu.Lock()
+	defer sharedDecodersMu.Unlock()
+	d, ok := sharedDecoders[in]
+	_, isFile := in.(*os.File)
+	switch {
+	case ok && isFile:
+		return d
+	case ok:
+		delete(sharedDecoders, in)
+		d.parked = false
+		return d
+	}
+	d = NewDecoder(in)
+	if isFile {
+		d.shared = true
+		sharedDecoders[in] = d
+	} else if !readAhead {
+		d.chunk = d.chunk[:1]
+		d.byteWise = true
+	}
+	return d
+}
+
+// put hands back a Decoder taken with takeDecoder, parking it if it is
+// still reading ahead or holds input not yet returned. Readers that cannot
+// be map keys are not parked.
+func (d *Decoder) put() {
+	busy := d.pump != nil || len(d.buf) > 0 || len(d.pending) > 0
+	if d.shared || !busy || !reflect.TypeOf(d.r).Comparable() {
+		return
+	}
+	sharedDecodersMu.Lock()
+	defer sharedDecodersMu.Unlock()
+	if !d.readFailed {
+		d.parked = true
+		sharedDecoders[d.r] = d
+	}
+}
+
+// release forgets a shared or parked Decoder whose reader has failed, and
+// keeps it from being parked again.
+func (d *Decoder) release() {
+	sharedDecodersMu.Lock()
+	defer sharedDecodersMu.Unlock()
+	if (d.shared || d.parked) && sharedDecoders[d.r] == d {
+		delete(sharedDecoders, d.r)
+	}
+	d.parked, d.readFailed = false, true
+}
+
+/** Close stops the Decoder. Input read but not yet decoded is discarded,
+ * and a goroutine reading ahead exits once the read it is waiting on
+ * returns. The reader itself is not closed. Reading from the Decoder
+ * afterwards returns os.ErrClosed.
+ *
+ * Example:
+ *   dec := termlib.NewDecoder(conn)
+ *   defer dec.Close()
+ */
+func (d *Decoder) Close() {
+	if isClosed(d.done) {
+		return
+	}
+	close(d.done)
+	d.release()
+	d.buf, d.pending, d.err = nil, nil, os.ErrClosed
+}
+
+/** ReadEvent returns the next event: a KeyEvent, MouseEvent, PasteEvent
+ * or FocusEvent.
+ *
+ * Returns:
+ *   Event — the decoded event.
+ *   error — the reader's error (such as io.EOF) once all input before it
+ *           has been decoded.
+ */
+func (d *Decoder) ReadEvent() (Event, error) {
+	ev, _, err := d.next()
+	return ev, err
+}
+
+/** ReadKey returns the next keystroke. Mouse, paste and focus reports are
+ * consumed and returned as KeyUnknown; modifiers are dropped, and key
+ * releases reported by the kitty keyboard protocol are skipped.
+ *
+ * Returns:
+ *   Key   — the keystroke.
+ *   error — the reader's error once all input before it has been decoded.
+ */
+func (d *Decoder) ReadKey() (Key, error) {
+	for {
+		ev, _, err := d.next()
+		k, ok := ev.(KeyEvent)
+		switch {
+		case !ok:
+			return KeyUnknown, err
+		case k.Action != KeyRelease:
+			return k.legacyKey(), err
+		}
+	}
+}
+
+// next decodes the next event. plain is true when the event is a single
+// character typed directly (including control characters) rather than
+// one decoded from an escape sequence or invalid input.
+func (d *Decoder) next() (ev Event, plain bool, err error) {
+	return d.nextUntil(nil)
+}
+
+// nextUntil is next, giving up with errStopped once stop is closed while
+// it waits for input. Undecoded input is kept for the next call.
+func (d *Decoder) nextUntil(stop <-chan struct{}) (ev Event, plain bool, err error) {
+	if len(d.pending) > 0 {
+		p := d.pending[0]
+		d.pending = d.pending[1:]
+		return p.ev, p.plain, nil
+	}
+	timedOut := false
+	for {
+		if len(d.buf) > 0 {
+			if ev, n, plain := decodeEvent(d.buf, timedOut || d.err != nil); n > 0 {
+				d.buf = d.buf[n:]
+				if _, ok := ev.(replyEvent); ok {
+					// A reply that came too late for its query.
+					timedOut = false
+					continue
+				}
+				return ev, plain, nil
+			}
+		} else if d.err != nil {
+			if d.shared {
+				d.release()
+			}
+			return nil, false, d.err
+		}
+		var timeout time.Duration
+		if len(d.buf) > 0 && escapeMayTimeOut(d.buf) {
+			timeout = EscapeTimeout()
+		}
+		timedOut = !d.fill(timeout, stop)
+		if timedOut && isClosed(stop) {
+			return nil, false, errStopped
+		}
+	}
+}
+
+// unread puts ev back, to be returned by the next call of next.
+func (d *Decoder) unread(ev Event, plain bool) {
+	d.pending = append([]pendingEvent{{ev, plain}}, d.pending...)
+}
+
+// escapeMayTimeOut reports whether the unfinished input b is decoded as it
+// stands once the escape timeout passes. That is any unfinished escape
+// sequence, since its start may be a whole key: ESC on its own is Escape,
+// and ESC [, ESC O, ESC P and ESC ] are also Alt+[, Alt+O, Alt+P and
+// Alt+]. A bracketed paste is the exception, as a long one may take a
+// while to arrive.
+func escapeMayTimeOut(b []byte) bool {
+	return b[0] == 0x1b && !bytes.HasPrefix(b, []byte("\x1b[200~"))
+}
+
+// awaitReply decodes input until done accepts a reply to a query or the
+// timeout passes, and reports whether done accepted one. Other events
+// decoded meanwhile are kept for next, and a read error is left for next
+// to report.
+func (d *Decoder) awaitReply(timeout time.Duration, done func(reply string) bool) bool {
+	deadline := time.Now().Add(timeout)
+	for {
+		for len(d.buf) > 0 {
+			ev, n, plain := decodeEvent(d.buf, false)
+			if n == 0 {
+				break
+			}
+			d.buf = d.buf[n:]
+			if r, ok := ev.(replyEvent); ok {
+				if done(string(r)) {
+					return true
+				}
+				continue
+			}
+			d.pending = append(d.pending, pendingEvent{ev, plain})
+		}
+		left := time.Until(deadline)
+		if d.err != nil || left <= 0 || !d.fill(left, nil) {
+			return false
+		}
+	}
+}
+
+// fill appends the next chunk of input to d.buf. With a positive timeout
+// it gives up and reports false if no input arrives in time, and likewise
+// when stop is closed first; a nil stop is never closed.
+func (d *Decoder) fill(timeout time.Duration, stop <-chan struct{}) bool {
+	if d.hasFd || d.byteWise {
+		if d.hasFd && (timeout > 0 || stop != nil) && !waitInput(d.fd, timeout, stop) {
+			return false
+		}
+		n, err := d.r.Read(d.chunk)
+		d.buf = append(d.buf, d.chunk[:n]...)
+		d.err = err
+		return true
+	}
+	if d.pump == nil {
+		d.pump = make(chan inputChunk, 1)
+		go d.readAhead()
+	}
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+	var c inputChunk
+	select {
+	case c = <-d.pump:
+	case <-expired:
+		return false
+	case <-stop:
+		return false
+	}
+	d.buf = append(d.buf, c.data...)
+	d.err = c.err
+	return true
+}
+
+// stopPollInterval is how often waitInput checks whether it should stop
+// waiting for a file descriptor.
+const stopPollInterval = 50 * time.Millisecond
+
+// waitInput reports whether fd has input ready within timeout, or with no
+// timeout at all, before stop is closed.
+func waitInput(fd uintptr, timeout time.Duration, stop <-chan struct{}) bool {
+	if stop == nil {
+		return waitReadable(fd, timeout)
+	}
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+	for !isClosed(stop) {
+		wait := stopPollInterval
+		if timeout > 0 {
+			left := time.Until(deadline)
+			if left <= 0 {
+				return false
+			}
+			wait = min(wait, left)
+		}
+		if waitReadable(fd, wait) {
+			return true
+		}
+	}
+	return false
+}
+
+// isClosed reports whether the channel c has been closed; a nil channel
+// never is.
+func isClosed(c <-chan struct{}) bool {
+	select {
+	case <-c:
+		return true
+	default:
+		return false
+	}
+}
+
+// readAhead reads from a reader without a file descriptor on behalf of
+// fill, so that fill can stop waiting when the escape timeout expires.
+func (d *Decoder) readAhead() {
+	for !isClosed(d.done) {
+		b := make([]byte, decoderChunkSize)
+		n, err := d.r.Read(b)
+		if err != nil {
+			d.release() // a parked Decoder is not read again
+		}
+		select {
+		case d.pump <- inputChunk{data: b[:n], err: err}:
+		case <-d.done:
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// decodeEvent decodes the event at the start of b and returns it with the
+// number of bytes it used. It returns n == 0 when b holds only the start of
+// an event; when final is true no more input is coming soon, and whatever
+// b holds is decoded as it stands. plain reports a directly typed
+// character, as for Decoder.next.
+func decodeEvent(b []byte, final bool) (ev Event, n int, plain bool) {
+	if b[0] != 0x1b {
+		if b[0] < utf8.RuneSelf {
+			return KeyEvent{Key: Key(b[0])}, 1, true
+		}
+		if !utf8.FullRune(b) && !final {
+			return nil, 0, false
+		}
+		r, size := utf8.DecodeRune(b)
+		if r == utf8.RuneError {
+			return KeyEvent{Key: KeyUnknown}, size, false
+		}
+		return KeyEvent{Key: Key(r)}, size, true
+	}
+
+	n = escSeqLen(b, final)
+	if n == 0 {
+		return nil, 0, false
+	}
+	seq, rest := string(b[1:n]), b[n:]
+	switch {
+	case seq == "[I":
+		return FocusEvent{Focused: true}, n, false
+	case seq == "[O":
+		return FocusEvent{Focused: false}, n, false
+	case seq == "[200~":
+		end := bytes.Index(rest, []byte(pasteEnd))
+		if end < 0 {
+			if !final {
+				return nil, 0, false
+			}
+			return PasteEvent{Text: string(rest)}, len(b), false
+		}
+		return PasteEvent{Text: string(rest[:end])}, n + end + len(pasteEnd), false
+	case seq == "[M":
+		// X10 mouse report: button, column and row bytes, each offset by 32.
+		if len(rest) < 3 {
+			if !final {
+				return nil, 0, false
+			}
+			return KeyEvent{Key: KeyUnknown}, len(b), false
+		}
+		return mouseFromCode(int(rest[0])-32, int(rest[1])-32, int(rest[2])-32, false), n + 3, false
+	case strings.HasPrefix(seq, "[?") && (strings.HasSuffix(seq, "u") || strings.HasSuffix(seq, "c") || strings.HasSuffix(seq, "$y")):
+		// Kitty keyboard flags, primary device attributes or a DEC mode.
+		return replyEvent(seq), n, false
+	case len(seq) > 1 && (seq[0] == 'P' || seq[0] == ']'):
+		// A DCS or OSC string, such as the XTVERSION or OSC 11 reply.
+		reply := strings.TrimSuffix(strings.TrimSuffix(seq, "\a"), "\x1b\\")
+		return replyEvent(reply), n, false
+	case strings.HasPrefix(seq, "[") && strings.HasSuffix(seq, "u"):
+		ev, plain := decodeKittyKey(seq)
+		return ev, n, plain
+	case strings.HasPrefix(seq, "[<"):
+		if ev, ok := parseSGRMouse(seq); ok {
+			return ev, n, false
+		}
+		return KeyEvent{Key: KeyUnknown}, n, false
+	}
+	seq, action := splitKeyAction(seq)
+	k, mod := decodeKeySeq(seq)
+	return KeyEvent{Key: k, Mod: mod, Action: action}, n, false
+}
+
+// escSeqLen returns the length of the escape sequence at the start of b,
+// including the ESC, or 0 when b ends before the sequence does. When final
+// is true an unfinished sequence takes up the rest of b. CSI sequences end
+// on a final byte, SS3 sequences on the first non-digit, and ESC followed
+// by any other character is Alt with that character.
+func escSeqLen(b []byte, final bool) int {
+	if len(b) >= 2 {
+		switch b[1] {
+		case '[':
+			for i := 2; i < len(b); i++ {
+				if i == 2 && b[i] == '[' {
+					continue // Linux console F1–F5: ESC [ [ A…E
+				}
+				if csiFinal(b[2 : i+1]) {
+					return i + 1
+				}
+			}
+		case 'O':
+			for i := 2; i < len(b); i++ {
+				if b[i] < '0' || b[i] > '9' { // digits are an old-style modifier
+					return i + 1
+				}
+			}
+		case 'P', ']':
+			// DCS and OSC strings run to ST, or for OSC also BEL. Only
+			// those a terminal sends in reply to a query are recognised;
+			// anything else is Alt+P or Alt+].
+			if len(b) == 2 {
+				break
+			}
+			if b[1] == 'P' && b[2] != '>' || b[1] == ']' && (b[2] < '0' || b[2] > '9') {
+				return 2
+			}
+			for i := 3; i < len(b); i++ {
+				if b[i] == '\a' && b[1] == ']' || b[i] == '\\' && b[i-1] == 0x1b {
+					return i + 1
+				}
+			}
+		case 0x1b:
+			if n := escSeqLen(b[1:], final); n > 0 {
+				return n + 1
+			}
+			return 0
+		default:
+			if utf8.FullRune(b[1:]) {
+				_, size := utf8.DecodeRune(b[1:])
+				return 1 + size
+			}
+		}
+	}
+	if final {
+		return len(b)
+	}
+	return 0
+}
+
+// csiFinal reports whether the last byte of seq, the bytes read so far
+// after ESC [, ends the sequence. Besides the standard final bytes this
+// accepts rxvt's "$", but only after a plain number, since elsewhere "$"
+// is an intermediate byte (as in DECRQM replies).
+func csiFinal(seq []byte) bool {
+	last := seq[len(seq)-1]
+	if last >= 0x40 && last <= 0x7e {
+		return true
+	}
+	if last != '$' || len(seq) == 1 {
+		return false
+	}
+	for _, c := range seq[:len(seq)-1] {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,198 @@
+// decoder_test.go — tests for the chunked input decoder.
+// Copyright (C) 2025 R. S. Doiel
+package termlib
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+// decodeAll reads every event from dec until an error.
+func decodeAll(t *testing.T, dec *Decoder) []Event {
+	t.Helper()
+	var evs []Event
+	for {
+		ev, err := dec.ReadEvent()
+		if err != nil {
+			if err != io.EOF {
+				t.Fatal(err)
+			}
+			return evs
+		}
+		evs = append(evs, ev)
+	}
+}
+
+func TestDecoderChunks(t *testing.T) {
+	const input = "a\x1b[1;5C中\x1b[200~x\ny\x1b[201~\x1b[<0;3;4M\x1b[M *%\x1bb"
+	want := []Event{
+		KeyEvent{Key: 'a'},
+		KeyEvent{Key: KeyRight, Mod: ModCtrl},
+		KeyEvent{Key: '中'},
+		PasteEvent{Text: "x\ny"},
+		MouseEvent{X: 3, Y: 4, Button: MouseLeft, Action: MousePress},
+		MouseEvent{X: 10, Y: 5, Button: MouseLeft, Action: MousePress},
+		KeyEvent{Key: 'b', Mod: ModAlt},
+	}
+	readers := map[string]io.Reader{
+		"whole":    strings.NewReader(input),
+		"one byte": iotest.OneByteReader(strings.NewReader(input)),
+		"half":     iotest.HalfReader(strings.NewReader(input)),
+	}
+	for name, r := range readers {
+		t.Run(name, func(t *testing.T) {
+			got := decodeAll(t, NewDecoder(r))
+			if len(got) != len(want) {
+				t.Fatalf("got %d events %#v, want %d", len(got), got, len(want))
+			}
+			for i := range want {
+				if got[i] != want[i] {
+					t.Errorf("event %d: got %#v, want %#v", i, got[i], want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDecoderTruncatedInput(t *testing.T) {
+	got := decodeAll(t, NewDecoder(strings.NewReader("\x1b[200~unterminated")))
+	if len(got) != 1 || got[0] != (PasteEvent{Text: "unterminated"}) {
+		t.Errorf("got %#v", got)
+	}
+	got = decodeAll(t, NewDecoder(strings.NewReader("\xff\x1b[12")))
+	if len(got) != 2 || got[0] != (KeyEvent{Key: KeyUnknown}) || got[1] != (KeyEvent{Key: KeyUnknown}) {
+		t.Errorf("got %#v", got)
+	}
+}
+
+func TestDecoderEscapeTimeoutWithoutFd(t *testing.T) {
+	r, w := io.Pipe()
+	defer w.Close()
+	dec := NewDecoder(r)
+	go w.Write([]byte("\x1b"))
+	start := time.Now()
+	k, err := dec.ReadKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k != Key(0x1b) {
+		t.Errorf("got %v, want bare ESC", k)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("ReadKey took %v", elapsed)
+	}
+	go w.Write([]byte("\x1b[A"))
+	if k, _ := dec.ReadKey(); k != KeyUp {
+		t.Errorf("got %v, want KeyUp", k)
+	}
+}
+
+func TestDecoderEscapePrefixTimesOut(t *testing.T) {
+	defer SetEscapeTimeout(0)
+	SetEscapeTimeout(20 * time.Millisecond)
+	for _, prefix := range []string{"\x1b[", "\x1bO"} {
+		r, w := io.Pipe()
+		dec := NewDecoder(r)
+		go w.Write([]byte(prefix))
+		ev, err := dec.ReadEvent()
+		if want := (KeyEvent{Key: Key(prefix[1]), Mod: ModAlt}); ev != want || err != nil {
+			t.Errorf("%q: got %#v, %v; want %#v", prefix, ev, err, want)
+		}
+		// The key typed next is decoded on its own.
+		go w.Write([]byte("j"))
+		if ev, _ := dec.ReadEvent(); ev != (KeyEvent{Key: 'j'}) {
+			t.Errorf("%q then j: got %#v", prefix, ev)
+		}
+		w.Close()
+	}
+}
+
+// sharedDecoder reports whether sharedDecoders holds a Decoder for in.
+func sharedDecoder(in io.Reader) bool {
+	sharedDecodersMu.Lock()
+	defer sharedDecodersMu.Unlock()
+	_, ok := sharedDecoders[in]
+	return ok
+}
+
+func TestSharedDecoderKeepsReadAhead(t *testing.T) {
+	in := pipeInput(t, "xy")
+	if k, _ := ReadKey(in); k != 'x' {
+		t.Errorf("got %v, want x", k)
+	}
+	// The first call read both bytes; the second must still see "y".
+	if k, _ := ReadKey(in); k != 'y' {
+		t.Errorf("got %v, want y", k)
+	}
+	if _, err := ReadKey(in); err != io.EOF {
+		t.Errorf("want io.EOF, got %v", err)
+	}
+	if sharedDecoder(in) {
+		t.Error("decoder not released after EOF")
+	}
+}
+
+func TestReadKeyWithoutSharedDecoder(t *testing.T) {
+	r := strings.NewReader("x\x1b[Ay")
+	for _, want := range []Key{'x', KeyUp, 'y'} {
+		if k, err := ReadKey(r); k != want || err != nil {
+			t.Errorf("got %v, %v; want %v", k, err, want)
+		}
+		if sharedDecoder(r) {
+			t.Fatal("decoder kept for a reader that is not a file")
+		}
+	}
+	if _, err := ReadKey(r); err != io.EOF {
+		t.Errorf("want io.EOF, got %v", err)
+	}
+}
+
+func TestParkedDecoderReleased(t *testing.T) {
+	r, w := io.Pipe()
+	events, stop := EventReader(r, nil)
+	go w.Write([]byte("a"))
+	<-events
+	stop()
+	// The read still waiting for input is kept for the next reader...
+	if !sharedDecoder(r) {
+		t.Fatal("decoder reading ahead not parked")
+	}
+	// ...until the reader fails.
+	w.Close()
+	deadline := time.Now().Add(2 * time.Second)
+	for sharedDecoder(r) {
+		if time.Now().After(deadline) {
+			t.Fatal("parked decoder not released")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestDecoderSkipsReplies(t *testing.T) {
+	// Late replies to queries are dropped; ESC P and ESC ] that do not
+	// start a reply are Alt+P and Alt+].
+	const input = "\x1bP>|xterm(390)\x1b\\\x1bPq\x1b]11;rgb:0/0/0\a\x1b[?2026;2$y\x1b]\x1bP"
+	want := []Event{
+		KeyEvent{Key: 'P', Mod: ModAlt},
+		KeyEvent{Key: 'q'},
+		KeyEvent{Key: ']', Mod: ModAlt},
+		KeyEvent{Key: 'P', Mod: ModAlt},
+	}
+	for name, r := range map[string]io.Reader{
+		"whole":    strings.NewReader(input),
+		"one byte": iotest.OneByteReader(strings.NewReader(input)),
+	} {
+		got := decodeAll(t, NewDecoder(r))
+		if len(got) != len(want) {
+			t.Fatalf("%s: got %#v", name, got)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("%s: event %d: got %#v, want %#v", name, i, got[i], want[i])
+			}
+		}
+	}
+}
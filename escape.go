@@ -0,0 +1,47 @@
+// escape.go — telling a lone Escape key apart from an escape sequence.
+// Copyright (C) 2025 R. S. Doiel
+package termlib
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// DefaultEscapeTimeout is the initial escape timeout; see SetEscapeTimeout.
+const DefaultEscapeTimeout = 50 * time.Millisecond
+
+// escTimeout holds the current escape timeout in nanoseconds.
+var escTimeout atomic.Int64
+
+func init() {
+	escTimeout.Store(int64(DefaultEscapeTimeout))
+}
+
+/** SetEscapeTimeout sets how long ReadKey, ReadEvent and LineEditor wait
+ * after an ESC byte for the rest of an escape sequence. Terminals send a
+ * whole sequence at once, so if nothing follows within the timeout the
+ * Escape key itself was pressed and it is reported straight away as
+ * Key(0x1b), without swallowing the next keystroke. The same goes for a
+ * sequence that stops partway: ESC [ on its own is Alt+[, for example.
+ *
+ * Values of 25–50 ms suit local terminals; raise it for slow remote links
+ * where sequences may be split in transit. A zero or negative duration
+ * restores DefaultEscapeTimeout.
+ *
+ * Parameters:
+ *   d (time.Duration) — how long to wait for the rest of a sequence.
+ *
+ * Example:
+ *   termlib.SetEscapeTimeout(25 * time.Millisecond) // snappy vi-style modes
+ */
+func SetEscapeTimeout(d time.Duration) {
+	if d <= 0 {
+		d = DefaultEscapeTimeout
+	}
+	escTimeout.Store(int64(d))
+}
+
+// EscapeTimeout returns the current escape timeout.
+func EscapeTimeout() time.Duration {
+	return time.Duration(escTimeout.Load())
+}
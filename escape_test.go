@@ -0,0 +1,49 @@
+// escape_test.go — tests for the escape timeout.
+// Copyright (C) 2025 R. S. Doiel
+package termlib
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestBareEscapeTimesOut(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	w.WriteString("\033")
+	start := time.Now()
+	k, err := ReadKey(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k != Key(0x1b) {
+		t.Errorf("got %v, want bare ESC", k)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("ReadKey took %v", elapsed)
+	}
+
+	// The key typed after Escape is not swallowed.
+	w.WriteString("j")
+	if k, _ := ReadKey(r); k != Key('j') {
+		t.Errorf("got %v, want j", k)
+	}
+}
+
+func TestSetEscapeTimeout(t *testing.T) {
+	defer SetEscapeTimeout(0)
+	SetEscapeTimeout(25 * time.Millisecond)
+	if got := EscapeTimeout(); got != 25*time.Millisecond {
+		t.Errorf("got %v", got)
+	}
+	SetEscapeTimeout(-1)
+	if got := EscapeTimeout(); got != DefaultEscapeTimeout {
+		t.Errorf("got %v, want default", got)
+	}
+}
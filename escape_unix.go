@@ -0,0 +1,30 @@
+//go:build !windows
+
+// escape_unix.go — waiting for input with a timeout using poll(2).
+// Copyright (C) 2025 R. S. Doiel
+package termlib
+
+import (
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// waitReadable reports whether fd has input (or end of file) ready within
+// d. Errors other than an interrupted call report true, so that the
+// caller's next read surfaces them.
+func waitReadable(fd uintptr, d time.Duration) bool {
+	deadline := time.Now().Add(d)
+	for {
+		ms := int(time.Until(deadline).Milliseconds())
+		if ms < 0 {
+			ms = 0
+		}
+		fds := []unix.PollFd{{Fd: int32(fd), Events: unix.POLLIN}}
+		n, err := unix.Poll(fds, ms)
+		if err == unix.EINTR {
+			continue
+		}
+		return err != nil || n > 0
+	}
+}
@@ -0,0 +1,18 @@
+//go:build windows
+
+// escape_windows.go — waiting for console input with a timeout.
+// Copyright (C) 2025 R. S. Doiel
+package termlib
+
+import (
+	"time"
+
+	"golang.org/x/sys/windows"
+)
+
+// waitReadable reports whether the handle fd is signalled within d. A
+// console input handle is signalled while it has unread input.
+func waitReadable(fd uintptr, d time.Duration) bool {
+	ev, err := windows.WaitForSingleObject(windows.Handle(fd), uint32(d.Milliseconds()))
+	return err != nil || ev != uint32(windows.WAIT_TIMEOUT)
+}
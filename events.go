@@ -0,0 +1,276 @@
+// events.go — a unified stream of keyboard, mouse, paste, focus and resize events.
+// Copyright (C) 2025 R. S. Doiel
+package termlib
+
+import (
+	"errors"
+	"io"
+	"sync"
+)
+
+/** Event is something that happened at the terminal: a keystroke, a mouse
+ * action, pasted text, a focus change or a window resize. Use a type
+ * switch to handle the concrete types.
+ *
+ * Example:
+ *   for ev := range events {
+ *       switch ev := ev.(type) {
+ *       case termlib.KeyEvent:    handleKey(ev.Key)
+ *       case termlib.MouseEvent:  handleClick(ev.X, ev.Y)
+ *       case termlib.PasteEvent:  insert(ev.Text)
+ *       case termlib.FocusEvent:  dimWhenBlurred(ev.Focused)
+ *       case termlib.ResizeEvent: redraw()
+ *       }
+ *   }
+ */
+type Event interface {
+	isEvent()
+}
+
+/** KeyEvent reports a keystroke and the modifier keys held with it.
+ *
+ * Action, Shifted and Base are only reported by terminals using the kitty
+ * keyboard protocol (see Terminal.EnableKittyKeyboard); otherwise every
+ * event is a KeyPress and the alternate keys are zero. With that protocol
+ * Key is the unshifted key, so Ctrl+I is Key('i') with ModCtrl rather than
+ * Tab, and Shifted holds the shifted character when it was requested.
+ */
+type KeyEvent struct {
+	Key     Key
+	Mod     Modifier
+	Action  KeyAction // press, repeat or release
+	Shifted Key       // the key with Shift applied, if reported
+	Base    Key       // the key in the standard PC-101 layout, if reported
+}
+
+// KeyAction tells a key being pressed apart from it auto-repeating or
+// being released.
+type KeyAction uint8
+
+const (
+	KeyPress   KeyAction = iota // the key went down
+	KeyRepeat                   // the key is held and repeating
+	KeyRelease                  // the key came up
+)
+
+// PasteEvent carries text pasted while bracketed paste mode is enabled,
+// delivered as one event rather than as individual keystrokes.
+type PasteEvent struct {
+	Text string
+}
+
+// FocusEvent reports the terminal window gaining or losing focus while
+// focus reporting is enabled (see Terminal.EnableFocusEvents).
+type FocusEvent struct {
+	Focused bool
+}
+
+func (KeyEvent) isEvent()    {}
+func (MouseEvent) isEvent()  {}
+func (PasteEvent) isEvent()  {}
+func (FocusEvent) isEvent()  {}
+func (ResizeEvent) isEvent() {}
+func (replyEvent) isEvent()  {}
+
+// replyEvent is a terminal's reply to a query, such as a device
+// attributes report. Replies are consumed by whoever sent the query and
+// never returned by ReadEvent.
+type replyEvent string
+
+// pasteEnd terminates the text of a bracketed paste.
+const pasteEnd = "\033[201~"
+
+/** ReadEvent reads one input event from in, which must already be in raw
+ * mode. It decodes keystrokes like ReadKey and additionally recognises
+ * mouse reports (SGR and X10 encodings), bracketed paste and focus in/out
+ * reports. in is read as by ReadKey.
+ *
+ * Parameters:
+ *   in (io.Reader) — terminal input in raw mode, typically os.Stdin.
+ *
+ * Returns:
+ *   Event — a KeyEvent, MouseEvent, PasteEvent or FocusEvent.
+ *   error — non-nil on I/O failure or EOF.
+ *
+ * Example:
+ *   ev, err := termlib.ReadEvent(os.Stdin)
+ */
+func ReadEvent(in io.Reader) (Event, error) {
+	dec := takeDecoder(in, false)
+	defer dec.put()
+	return dec.ReadEvent()
+}
+
+/** EventReader starts a goroutine that reads events from in and sends them
+ * on the returned channel. in must already be in raw mode. When t is not
+ * nil, the terminal's window is also watched and a ResizeEvent is sent
+ * after each size change. The channel is closed when in returns an error
+ * (including EOF) or once stop is called.
+ *
+ * stop ends the goroutines and waits for them to exit, after which in may
+ * be read directly again: an event read but not yet received is kept for
+ * the next read. stop is safe to call more than once.
+ *
+ * Parameters:
+ *   in (io.Reader)  — terminal input in raw mode, typically os.Stdin.
+ *   t  (*Terminal)  — terminal to watch for resizes; may be nil.
+ *
+ * Returns:
+ *   <-chan Event — receive events from this channel.
+ *   stop (func()) — stops reading.
+ *
+ * Example:
+ *   restore, _ := termlib.EnterRawMode(os.Stdin)
+ *   defer restore()
+ *   events, stop := termlib.EventReader(os.Stdin, term)
+ *   defer stop()
+ *   for ev := range events {
+ *       if k, ok := ev.(termlib.KeyEvent); ok && k.Key == termlib.Key('q') {
+ *           break
+ *       }
+ *   }
+ */
+func EventReader(in io.Reader, t *Terminal) (<-chan Event, func()) {
+	ch := make(chan Event, 8)
+	r := newInputReader()
+	if t != nil {
+		resized, stopResize := t.WatchResize()
+		r.wg.Add(1)
+		go func() {
+			defer r.wg.Done()
+			defer stopResize()
+			for {
+				select {
+				case <-r.stop:
+					return
+				case ev := <-resized:
+					select {
+					case ch <- ev:
+					case <-r.stop:
+						return
+					}
+				}
+			}
+		}()
+	}
+	r.read(in, func(ev Event, plain bool) bool {
+		select {
+		case ch <- ev:
+			return true
+		case <-r.stop:
+			return false
+		}
+	})
+	r.closeWhenDone(func() { close(ch) })
+	return ch, r.Stop
+}
+
+// errStopped is returned by Decoder.nextUntil when it is told to stop.
+var errStopped = errors.New("termlib: reading stopped")
+
+// inputReader runs the goroutines behind EventReader and KeyReader: one
+// reading the input and any others sending to the same channel.
+type inputReader struct {
+	stop chan struct{} // closed to make every goroutine exit
+	once sync.Once
+	wg   sync.WaitGroup
+	done chan struct{} // closed once they all have, and the channel is closed
+}
+
+// newInputReader returns an inputReader with no goroutines yet.
+func newInputReader() *inputReader {
+	return &inputReader{stop: make(chan struct{}), done: make(chan struct{})}
+}
+
+// halt tells the goroutines to exit.
+func (r *inputReader) halt() {
+	r.once.Do(func() { close(r.stop) })
+}
+
+// Stop tells the goroutines to exit and waits until they have.
+func (r *inputReader) Stop() {
+	r.halt()
+	<-r.done
+}
+
+// read starts the goroutine decoding events from in and handing each to
+// deliver, until in fails or r is stopped. deliver reports false if r was
+// stopped before it could pass the event on; the event is then put back
+// for the next read of in. When in fails, the other goroutines are
+// stopped too.
+func (r *inputReader) read(in io.Reader, deliver func(ev Event, plain bool) bool) {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		defer r.halt()
+		dec := takeDecoder(in, true)
+		defer dec.put()
+		for {
+			ev, plain, err := dec.nextUntil(r.stop)
+			if err != nil {
+				return
+			}
+			if !deliver(ev, plain) {
+				dec.unread(ev, plain)
+				return
+			}
+		}
+	}()
+}
+
+// closeWhenDone calls closeCh once every goroutine has exited, so that
+// none of them can send on the closed channel.
+func (r *inputReader) closeWhenDone(closeCh func()) {
+	go func() {
+		r.wg.Wait()
+		closeCh()
+		close(r.done)
+	}()
+}
+
+/** EnableFocusEvents asks the terminal to report when its window gains or
+ * loses focus. Reports arrive as FocusEvent values from ReadEvent and
+ * EventReader. The request is written immediately.
+ *
+ * Example:
+ *   term.EnableFocusEvents()
+ *   defer term.DisableFocusEvents()
+ */
+func (t *Terminal) EnableFocusEvents() {
+	t.writeControl("\033[?1004h")
+}
+
+// DisableFocusEvents turns focus reporting off again.
+func (t *Terminal) DisableFocusEvents() {
+	t.writeControl("\033[?1004l")
+}
+
+/** EnableBracketedPaste asks the terminal to mark pasted text, so that it
+ * arrives as a single PasteEvent from ReadEvent and EventReader instead of
+ * as keystrokes. LineEditor.Prompt enables it for itself. The request is
+ * written immediately.
+ *
+ * Example:
+ *   term.EnableBracketedPaste()
+ *   defer term.DisableBracketedPaste()
+ */
+func (t *Terminal) EnableBracketedPaste() {
+	t.writeControl("\033[?2004h")
+}
+
+// DisableBracketedPaste turns bracketed paste mode off again.
+func (t *Terminal) DisableBracketedPaste() {
+	t.writeControl("\033[?2004l")
+}
+
+// writeControl writes a terminal mode sequence straight to the output,
+// outside of the frame diffing done by Refresh. Nothing is written in
+// OutputLines mode.
+func (t *Terminal) writeControl(seq string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.output == OutputLines {
+		return
+	}
+	t.write([]byte(seq))
+}
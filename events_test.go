@@ -0,0 +1,155 @@
+// events_test.go — tests for event decoding.
+// Copyright (C) 2025 R. S. Doiel
+package termlib
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// pipeInput returns the read end of a pipe preloaded with data.
+func pipeInput(t *testing.T, data string) *os.File {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { r.Close() })
+	if _, err := w.WriteString(data); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	return r
+}
+
+func TestReadEvent(t *testing.T) {
+	in := pipeInput(t, "a\033[A\033[I\033[O\033[200~hi\nthere\033[201~\033[<0;10;5M\033[<0;10;5m\033[<64;3;4M\033[<35;7;8M")
+	want := []Event{
+		KeyEvent{Key: Key('a')},
+		KeyEvent{Key: KeyUp},
+		FocusEvent{Focused: true},
+		FocusEvent{Focused: false},
+		PasteEvent{Text: "hi\nthere"},
+		MouseEvent{X: 10, Y: 5, Button: MouseLeft, Action: MousePress},
+		MouseEvent{X: 10, Y: 5, Button: MouseLeft, Action: MouseRelease},
+		MouseEvent{X: 3, Y: 4, Button: MouseWheelUp, Action: MousePress},
+		MouseEvent{X: 7, Y: 8, Button: MouseNone, Action: MouseMotion},
+	}
+	for i, w := range want {
+		got, err := ReadEvent(in)
+		if err != nil {
+			t.Fatalf("event %d: %v", i, err)
+		}
+		if got != w {
+			t.Errorf("event %d: got %#v, want %#v", i, got, w)
+		}
+	}
+	if _, err := ReadEvent(in); err == nil {
+		t.Error("expected error at EOF")
+	}
+}
+
+func TestEventReaderCloses(t *testing.T) {
+	in := pipeInput(t, "xy")
+	var keys []Key
+	events, stop := EventReader(in, nil)
+	defer stop()
+	for ev := range events {
+		if k, ok := ev.(KeyEvent); ok {
+			keys = append(keys, k.Key)
+		}
+	}
+	if len(keys) != 2 || keys[0] != 'x' || keys[1] != 'y' {
+		t.Errorf("got keys %v", keys)
+	}
+}
+
+func TestEventReaderStop(t *testing.T) {
+	// One input with a file descriptor and one read through the Decoder's
+	// read-ahead goroutine.
+	fr, fw, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fr.Close()
+	defer fw.Close()
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	inputs := map[string]struct {
+		r io.Reader
+		w io.Writer
+	}{"file": {fr, fw}, "pipe": {pr, pw}}
+
+	for name, in := range inputs {
+		events, stop := EventReader(in.r, New(&bytes.Buffer{}))
+		go in.w.Write([]byte("a"))
+		if ev := <-events; ev != (KeyEvent{Key: 'a'}) {
+			t.Errorf("%s: got %#v", name, ev)
+		}
+		stopped := make(chan struct{})
+		go func() { stop(); close(stopped) }()
+		select {
+		case <-stopped:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("%s: stop blocked", name)
+		}
+		stop()
+		if _, ok := <-events; ok {
+			t.Errorf("%s: channel not closed", name)
+		}
+		// Input typed after stop is left for the next reader.
+		go in.w.Write([]byte("b"))
+		if k, err := ReadKey(in.r); k != 'b' || err != nil {
+			t.Errorf("%s: got %v, %v; want b", name, k, err)
+		}
+	}
+}
+
+func TestKeyReader(t *testing.T) {
+	keys := KeyReader(strings.NewReader("ab"))
+	var got []Key
+	for k := range keys {
+		got = append(got, k)
+	}
+	if len(got) != 2 || got[0] != 'a' || got[1] != 'b' {
+		t.Errorf("got %v, want [a b]", got)
+	}
+}
+
+func TestKeyReaderStop(t *testing.T) {
+	r, w := io.Pipe()
+	defer w.Close()
+	keys, stop := KeyReaderStop(r)
+	go w.Write([]byte("\033[97;5:3ux"))
+	if k := <-keys; k != 'x' {
+		t.Errorf("got %v, want x", k)
+	}
+	stop()
+	if _, ok := <-keys; ok {
+		t.Error("channel not closed")
+	}
+}
+
+func TestEnableFocusEvents(t *testing.T) {
+	var out bytes.Buffer
+	term := New(&out)
+	term.EnableFocusEvents()
+	term.DisableFocusEvents()
+	if got := out.String(); got != "\033[?1004h\033[?1004l" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestEnableBracketedPaste(t *testing.T) {
+	var out bytes.Buffer
+	term := New(&out)
+	term.EnableBracketedPaste()
+	term.DisableBracketedPaste()
+	if got := out.String(); got != "\033[?2004h\033[?2004l" {
+		t.Errorf("got %q", got)
+	}
+}
@@ -4,4 +4,4 @@ go 1.26.2
 
 require golang.org/x/term v0.38.0
 
-require golang.org/x/sys v0.39.0 // indirect
+require golang.org/x/sys v0.39.0
@@ -3,7 +3,9 @@
 package termlib
 
 import (
-	"os"
+	"io"
+	"strconv"
+	"strings"
 	"unicode/utf8"
 )
 
@@ -11,7 +13,8 @@ import (
  * characters the value equals the rune value, so Key('q') is the letter q.
  * Control characters retain their ASCII values (e.g. Key(0x03) is Ctrl+C).
  * Special keys such as arrow keys use values >= 1000 defined as constants
- * below.
+ * below. Modifier keys held with a key are reported separately, in the Mod
+ * field of a KeyEvent (see ReadEvent).
  *
  * Example:
  *   k, _ := termlib.ReadKey(os.Stdin)
@@ -34,15 +37,125 @@ const (
 	KeyEnd          // End key
 	KeyPageUp       // Page Up key
 	KeyPageDown     // Page Down key
+	KeyInsert       // Insert key
+	KeyDelete       // Delete (forward delete) key
+	KeyBacktab      // Shift+Tab
+	KeyF1           // function keys F1–F12
+	KeyF2
+	KeyF3
+	KeyF4
+	KeyF5
+	KeyF6
+	KeyF7
+	KeyF8
+	KeyF9
+	KeyF10
+	KeyF11
+	KeyF12
 )
 
+/** Modifier is a set of modifier keys held down with a keystroke. Values
+ * combine with bitwise OR and match the xterm modifier encoding, so
+ * Ctrl+Shift+Left reports ModCtrl|ModShift.
+ *
+ * Alt combinations are recognised both from xterm modifier parameters
+ * (ESC [ 1 ; 3 D) and from the ESC prefix most terminals send for
+ * Alt+letter. Ctrl+letter keeps its traditional control character value
+ * (Ctrl+A is Key(0x01)) and does not set ModCtrl, except under the kitty
+ * keyboard protocol, which reports it as Key('a') with ModCtrl.
+ *
+ * Example:
+ *   ev, _ := termlib.ReadEvent(os.Stdin)
+ *   if k, ok := ev.(termlib.KeyEvent); ok && k.Key == termlib.KeyLeft && k.Mod&termlib.ModCtrl != 0 {
+ *       moveWordLeft()
+ *   }
+ */
+type Modifier uint8
+
+const (
+	ModShift Modifier = 1 << iota // Shift
+	ModAlt                        // Alt (Option on macOS)
+	ModCtrl                       // Control
+	ModMeta                       // Meta, or Super/Hyper under the kitty protocol
+)
+
+// modifierParam converts an xterm or kitty modifier parameter, which is
+// one more than a bit set, to Modifiers. Kitty's Super, Hyper and Meta
+// all count as ModMeta; Caps Lock and Num Lock are not modifiers here.
+func modifierParam(n int) Modifier {
+	bits := n - 1
+	mod := Modifier(bits & 0x0f)
+	if bits&0x30 != 0 { // kitty Hyper, Meta
+		mod |= ModMeta
+	}
+	return mod
+}
+
+// legacyKey returns the key a legacy encoding would have sent for e,
+// folding the kitty protocol's Ctrl+letter back to a control character
+// and its Shift+letter to the shifted character. Other events keep Key.
+func (e KeyEvent) legacyKey() Key {
+	k := e.Key
+	if k >= KeyUnknown {
+		return k
+	}
+	if e.Mod&ModShift != 0 {
+		switch {
+		case e.Shifted != 0:
+			k = e.Shifted
+		case k >= 'a' && k <= 'z':
+			k -= 'a' - 'A'
+		}
+	}
+	if e.Mod&ModCtrl != 0 {
+		switch {
+		case k >= 'a' && k <= 'z':
+			k -= 'a' - 1
+		case k >= '@' && k <= '_':
+			k -= '@'
+		case k == ' ':
+			k = 0
+		case k == '?':
+			k = 0x7f
+		}
+	}
+	return k
+}
+
+// String returns the modifiers in the conventional "Ctrl+Alt+Shift+" form,
+// with a trailing "+" so that it can prefix a key name.
+func (m Modifier) String() string {
+	var sb strings.Builder
+	for _, n := range []struct {
+		mod  Modifier
+		name string
+	}{{ModCtrl, "Ctrl+"}, {ModAlt, "Alt+"}, {ModShift, "Shift+"}, {ModMeta, "Meta+"}} {
+		if m&n.mod != 0 {
+			sb.WriteString(n.name)
+		}
+	}
+	return sb.String()
+}
+
 /** ReadKey reads exactly one keystroke from in, which must already be in raw
  * mode (see EnterRawMode). Multi-byte escape sequences (arrow keys, etc.)
  * are consumed and returned as a single Key constant. Multi-byte UTF-8
- * printable characters are decoded and returned as Key(rune).
+ * printable characters are decoded and returned as Key(rune). Modifiers
+ * are dropped, so Ctrl+Left returns KeyLeft and Alt+x returns Key('x');
+ * use ReadEvent to see them. Under the kitty keyboard protocol, key
+ * releases are skipped and Ctrl+letter is still returned as a control
+ * character.
+ *
+ * When in is an *os.File, input is read in chunks by a Decoder shared by
+ * every ReadKey, ReadEvent and LineEditor reading from it, so keys typed
+ * ahead are kept for the next call rather than lost. Other readers are
+ * read a byte at a time, so that nothing is left over between calls, and
+ * without the escape timeout unless they have a file descriptor; a
+ * program reading a network connection should keep a Decoder of its own
+ * (see NewDecoder).
  *
  * Parameters:
- *   in (*os.File) — input file in raw mode, typically os.Stdin.
+ *   in (io.Reader) — terminal input in raw mode, typically os.Stdin.
  *
  * Returns:
  *   Key   — the keystroke; KeyUnknown for unrecognised escape sequences.
@@ -53,54 +166,182 @@ const (
  *   defer restore()
  *   k, err := termlib.ReadKey(os.Stdin)
  */
-func ReadKey(in *os.File) (Key, error) {
-	b := make([]byte, 1)
-	if _, err := in.Read(b); err != nil {
-		return KeyUnknown, err
-	}
+func ReadKey(in io.Reader) (Key, error) {
+	dec := takeDecoder(in, false)
+	defer dec.put()
+	return dec.ReadKey()
+}
 
+// decodeKeySeq maps an escape sequence, without its leading ESC, to a key
+// and its modifiers. An empty sequence is a bare ESC and returns
+// Key(0x1b); unrecognised sequences return KeyUnknown.
+func decodeKeySeq(seq string) (Key, Modifier) {
 	switch {
-	case b[0] == 0x1b: // ESC or escape sequence
-		seq := readKeyEscSeq(in)
-		switch seq {
-		case "[A", "OA":
-			return KeyUp, nil
-		case "[B", "OB":
-			return KeyDown, nil
-		case "[C", "OC":
-			return KeyRight, nil
-		case "[D", "OD":
-			return KeyLeft, nil
-		case "[H", "OH", "[1~":
-			return KeyHome, nil
-		case "[F", "OF", "[4~":
-			return KeyEnd, nil
-		case "[5~":
-			return KeyPageUp, nil
-		case "[6~":
-			return KeyPageDown, nil
-		default:
-			return Key(0x1b), nil // bare ESC
+	case seq == "":
+		return Key(0x1b), 0
+	case seq[0] == 0x1b: // ESC ESC …: Alt with an escape sequence or ESC
+		k, mod := decodeKeySeq(seq[1:])
+		return k, mod | ModAlt
+	case seq[0] != '[' && seq[0] != 'O' || len(seq) == 1: // Alt+character
+		r, _ := utf8.DecodeRuneInString(seq)
+		return Key(r), ModAlt
+	}
+	if k, ok := rxvtKeys[seq]; ok {
+		return k.key, k.mod
+	}
+	var rxvtMod Modifier
+	if n := len(seq); n > 1 && seq[0] == '[' {
+		// rxvt marks modified "~" keys with a different final byte.
+		switch seq[n-1] {
+		case '$':
+			rxvtMod = ModShift
+		case '^':
+			rxvtMod = ModCtrl
+		case '@':
+			rxvtMod = ModCtrl | ModShift
 		}
-
-	case b[0] >= 0xc0: // UTF-8 multi-byte lead byte
-		r := readKeyUTF8Tail(in, b[0])
-		if r == utf8.RuneError {
-			return KeyUnknown, nil
+		if rxvtMod != 0 {
+			seq = seq[:n-1] + "~"
 		}
-		return Key(r), nil
+	}
+	base, mod := splitKeyModifier(seq)
+	return keyForSeq(base), mod | rxvtMod
+}
+
+// rxvtKeys are the modified arrow keys of rxvt and urxvt, which use their
+// own final bytes rather than xterm modifier parameters.
+var rxvtKeys = map[string]struct {
+	key Key
+	mod Modifier
+}{
+	"[a": {KeyUp, ModShift}, "[b": {KeyDown, ModShift},
+	"[c": {KeyRight, ModShift}, "[d": {KeyLeft, ModShift},
+	"Oa": {KeyUp, ModCtrl}, "Ob": {KeyDown, ModCtrl},
+	"Oc": {KeyRight, ModCtrl}, "Od": {KeyLeft, ModCtrl},
+}
+
+// splitKeyModifier removes an xterm modifier parameter from seq, returning
+// the unmodified sequence and the modifiers. "[1;5C" becomes "[C" with
+// ModCtrl, "[5;3~" becomes "[5~" with ModAlt, and the older SS3 form
+// "O5P" becomes "OP" with ModCtrl.
+func splitKeyModifier(seq string) (string, Modifier) {
+	if len(seq) < 2 {
+		return seq, 0
+	}
+	params, final := seq[1:len(seq)-1], seq[len(seq)-1:]
+	if seq[0] == 'O' && params != "" {
+		params = "1;" + params
+	}
+	i := strings.IndexByte(params, ';')
+	if i < 0 {
+		return seq, 0
+	}
+	n, err := strconv.Atoi(params[i+1:])
+	if err != nil || n < 1 {
+		return seq, 0
+	}
+	mod := modifierParam(n)
+	if params[:i] == "1" && final != "~" {
+		return seq[:1] + final, mod
+	}
+	return seq[:1] + params[:i] + final, mod
+}
 
-	default:
-		return Key(b[0]), nil
+// keyForSeq maps an unmodified escape sequence to a key, returning
+// KeyUnknown when it is not recognised. It covers the encodings used by
+// xterm and its descendants, VT220, rxvt and the Linux console.
+func keyForSeq(seq string) Key {
+	switch seq {
+	case "[A", "OA":
+		return KeyUp
+	case "[B", "OB":
+		return KeyDown
+	case "[C", "OC":
+		return KeyRight
+	case "[D", "OD":
+		return KeyLeft
+	case "[H", "OH", "[1~", "[7~":
+		return KeyHome
+	case "[F", "OF", "[4~", "[8~":
+		return KeyEnd
+	case "[5~":
+		return KeyPageUp
+	case "[6~":
+		return KeyPageDown
+	case "[2~":
+		return KeyInsert
+	case "[3~":
+		return KeyDelete
+	case "[Z":
+		return KeyBacktab
+	case "OP", "[P", "[11~", "[[A":
+		return KeyF1
+	case "OQ", "[Q", "[12~", "[[B":
+		return KeyF2
+	case "OR", "[R", "[13~", "[[C":
+		return KeyF3
+	case "OS", "[S", "[14~", "[[D":
+		return KeyF4
+	case "[15~", "[[E":
+		return KeyF5
+	case "[17~":
+		return KeyF6
+	case "[18~":
+		return KeyF7
+	case "[19~":
+		return KeyF8
+	case "[20~":
+		return KeyF9
+	case "[21~":
+		return KeyF10
+	case "[23~":
+		return KeyF11
+	case "[24~":
+		return KeyF12
 	}
+	if len(seq) == 2 && seq[0] == 'O' {
+		if k, ok := keypadKeys[seq[1]]; ok {
+			return k
+		}
+	}
+	return KeyUnknown
+}
+
+// keypadKeys maps the final byte of the SS3 sequences sent by the numeric
+// keypad in application mode to the key printed on it.
+var keypadKeys = map[byte]Key{
+	'M': Key('\r'), 'X': Key('='), 'j': Key('*'), 'k': Key('+'),
+	'l': Key(','), 'm': Key('-'), 'n': Key('.'), 'o': Key('/'),
+	'p': Key('0'), 'q': Key('1'), 'r': Key('2'), 's': Key('3'),
+	't': Key('4'), 'u': Key('5'), 'v': Key('6'), 'w': Key('7'),
+	'x': Key('8'), 'y': Key('9'),
+}
+
+/** EnableKeypadMode switches the numeric keypad into application mode,
+ * so that its keys can be told apart from the main keyboard. ReadKey
+ * decodes them back to the characters printed on the keys. The request is
+ * written immediately.
+ *
+ * Example:
+ *   term.EnableKeypadMode()
+ *   defer term.DisableKeypadMode()
+ */
+func (t *Terminal) EnableKeypadMode() {
+	t.writeControl("\033=")
+}
+
+// DisableKeypadMode returns the numeric keypad to normal (numeric) mode.
+func (t *Terminal) DisableKeypadMode() {
+	t.writeControl("\033>")
 }
 
 /** KeyReader starts a goroutine that reads keystrokes from in and sends them
  * on the returned channel. in must already be in raw mode. The goroutine
  * exits and closes the channel when in returns an error (including EOF).
+ * Use KeyReaderStop to end it sooner.
  *
  * Parameters:
- *   in (*os.File) — input file in raw mode, typically os.Stdin.
+ *   in (io.Reader) — terminal input in raw mode, typically os.Stdin.
  *
  * Returns:
  *   <-chan Key — receive keystrokes from this channel.
@@ -113,66 +354,49 @@ func ReadKey(in *os.File) (Key, error) {
  *       if k == Key('q') { break }
  *   }
  */
-func KeyReader(in *os.File) <-chan Key {
-	ch := make(chan Key, 8)
-	go func() {
-		defer close(ch)
-		for {
-			k, err := ReadKey(in)
-			if err != nil {
-				return
-			}
-			ch <- k
-		}
-	}()
-	return ch
+func KeyReader(in io.Reader) <-chan Key {
+	keys, _ := KeyReaderStop(in)
+	return keys
 }
 
-// readKeyEscSeq reads the bytes following an ESC and returns a short string
-// identifying the sequence, e.g. "[A" for up-arrow.
-func readKeyEscSeq(in *os.File) string {
-	b := make([]byte, 1)
-	if _, err := in.Read(b); err != nil {
-		return ""
-	}
-	switch b[0] {
-	case '[': // CSI sequence
-		var seq []byte
-		for {
-			if _, err := in.Read(b); err != nil {
-				break
-			}
-			seq = append(seq, b[0])
-			if (b[0] >= 'A' && b[0] <= 'Z') || (b[0] >= 'a' && b[0] <= 'z') || b[0] == '~' {
-				break
+/** KeyReaderStop is KeyReader with a stop function, which ends the
+ * goroutine and waits for it to exit, after which in may be read directly
+ * again, as EventReader's does. The channel is closed when in returns an
+ * error or once stop is called.
+ *
+ * Parameters:
+ *   in (io.Reader) — terminal input in raw mode, typically os.Stdin.
+ *
+ * Returns:
+ *   <-chan Key    — receive keystrokes from this channel.
+ *   stop (func()) — stops reading; safe to call more than once.
+ *
+ * Example:
+ *   keys, stop := termlib.KeyReaderStop(os.Stdin)
+ *   defer stop()
+ *   for k := range keys {
+ *       if k == Key('q') { break }
+ *   }
+ */
+func KeyReaderStop(in io.Reader) (<-chan Key, func()) {
+	ch := make(chan Key, 8)
+	r := newInputReader()
+	r.read(in, func(ev Event, plain bool) bool {
+		// As ReadKey: releases are skipped and other events are KeyUnknown.
+		k := KeyUnknown
+		if kev, ok := ev.(KeyEvent); ok {
+			if kev.Action == KeyRelease {
+				return true
 			}
+			k = kev.legacyKey()
 		}
-		return "[" + string(seq)
-	case 'O': // SS3 sequence (application cursor keys)
-		if _, err := in.Read(b); err != nil {
-			return ""
+		select {
+		case ch <- k:
+			return true
+		case <-r.stop:
+			return false
 		}
-		return "O" + string(b[:1])
-	default:
-		return ""
-	}
-}
-
-// readKeyUTF8Tail reads continuation bytes for a multi-byte UTF-8 sequence
-// whose lead byte is lead, and returns the decoded rune.
-func readKeyUTF8Tail(in *os.File, lead byte) rune {
-	var tailLen int
-	switch {
-	case lead >= 0xf0:
-		tailLen = 3
-	case lead >= 0xe0:
-		tailLen = 2
-	default:
-		tailLen = 1
-	}
-	tail := make([]byte, tailLen)
-	in.Read(tail)
-	full := append([]byte{lead}, tail...)
-	r, _ := utf8.DecodeRune(full)
-	return r
+	})
+	r.closeWhenDone(func() { close(ch) })
+	return ch, r.Stop
 }
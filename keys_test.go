@@ -0,0 +1,137 @@
+// keys_test.go — tests for key sequence decoding.
+// Copyright (C) 2025 R. S. Doiel
+package termlib
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDecodeKeySeq(t *testing.T) {
+	tests := []struct {
+		seq string
+		key Key
+		mod Modifier
+	}{
+		{"", Key(0x1b), 0},
+		{"[A", KeyUp, 0},
+		{"OD", KeyLeft, 0},
+		{"[1;5D", KeyLeft, ModCtrl},
+		{"[1;5C", KeyRight, ModCtrl},
+		{"[1;2A", KeyUp, ModShift},
+		{"[1;3H", KeyHome, ModAlt},
+		{"[1;6F", KeyEnd, ModCtrl | ModShift},
+		{"[5;3~", KeyPageUp, ModAlt},
+		{"[1;5~", KeyHome, ModCtrl},
+		{"[a", KeyUp, ModShift},
+		{"Od", KeyLeft, ModCtrl},
+		{"x", Key('x'), ModAlt},
+		{"é", Key('é'), ModAlt},
+		{"\x1b[D", KeyLeft, ModAlt},
+		{"[99z", KeyUnknown, 0},
+		{"[", Key('['), ModAlt}, // ESC [ once the escape timeout has passed
+		{"O", Key('O'), ModAlt},
+	}
+	for _, tt := range tests {
+		k, mod := decodeKeySeq(tt.seq)
+		if k != tt.key || mod != tt.mod {
+			t.Errorf("decodeKeySeq(%q) = %v, %v; want %v, %v", tt.seq, k, mod, tt.key, tt.mod)
+		}
+	}
+}
+
+func TestReadKeyDropsModifiers(t *testing.T) {
+	in := pipeInput(t, "\033[1;5C\033x")
+	for _, want := range []Key{KeyRight, Key('x')} {
+		k, err := ReadKey(in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if k != want {
+			t.Errorf("got %v, want %v", k, want)
+		}
+	}
+}
+
+func TestReadEventModifiers(t *testing.T) {
+	in := pipeInput(t, "\033[1;5D\033b")
+	for _, want := range []KeyEvent{{Key: KeyLeft, Mod: ModCtrl}, {Key: Key('b'), Mod: ModAlt}} {
+		ev, err := ReadEvent(in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ev != want {
+			t.Errorf("got %#v, want %#v", ev, want)
+		}
+	}
+}
+
+func TestModifierString(t *testing.T) {
+	if got := (ModShift | ModCtrl).String(); got != "Ctrl+Shift+" {
+		t.Errorf("got %q", got)
+	}
+	if got := Modifier(0).String(); got != "" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestDecodeKeySeqSpecialKeys(t *testing.T) {
+	tests := []struct {
+		seq string
+		key Key
+		mod Modifier
+	}{
+		{"OP", KeyF1, 0},
+		{"[11~", KeyF1, 0},
+		{"[[A", KeyF1, 0},
+		{"[1;5P", KeyF1, ModCtrl},
+		{"O2Q", KeyF2, ModShift},
+		{"[14~", KeyF4, 0},
+		{"[[E", KeyF5, 0},
+		{"[15~", KeyF5, 0},
+		{"[17~", KeyF6, 0},
+		{"[21~", KeyF10, 0},
+		{"[24;5~", KeyF12, ModCtrl},
+		{"[2~", KeyInsert, 0},
+		{"[3~", KeyDelete, 0},
+		{"[3;2~", KeyDelete, ModShift},
+		{"[3^", KeyDelete, ModCtrl},
+		{"[2$", KeyInsert, ModShift},
+		{"[7~", KeyHome, 0},
+		{"[8~", KeyEnd, 0},
+		{"[Z", KeyBacktab, 0},
+		{"Op", Key('0'), 0},
+		{"Oy", Key('9'), 0},
+		{"OM", Key('\r'), 0},
+		{"Ok", Key('+'), 0},
+	}
+	for _, tt := range tests {
+		k, mod := decodeKeySeq(tt.seq)
+		if k != tt.key || mod != tt.mod {
+			t.Errorf("decodeKeySeq(%q) = %v, %v; want %v, %v", tt.seq, k, mod, tt.key, tt.mod)
+		}
+	}
+}
+
+func TestReadKeySpecialSequences(t *testing.T) {
+	in := pipeInput(t, "\033[[A\033O5P\033[2$\033[24~\033[Zq")
+	for _, want := range []Key{KeyF1, KeyF1, KeyInsert, KeyF12, KeyBacktab, Key('q')} {
+		k, err := ReadKey(in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if k != want {
+			t.Errorf("got %v, want %v", k, want)
+		}
+	}
+}
+
+func TestEnableKeypadMode(t *testing.T) {
+	var out bytes.Buffer
+	term := New(&out)
+	term.EnableKeypadMode()
+	term.DisableKeypadMode()
+	if got := out.String(); got != "\033=\033>" {
+		t.Errorf("got %q", got)
+	}
+}
@@ -0,0 +1,206 @@
+// kitty.go — the kitty keyboard protocol.
+// Copyright (C) 2025 R. S. Doiel
+package termlib
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/** KeyboardFlags select the enhancements of the kitty keyboard protocol
+ * requested by EnableKittyKeyboard. They combine with bitwise OR and match
+ * the protocol's progressive enhancement flags.
+ *
+ * Example:
+ *   term.EnableKittyKeyboard(os.Stdin, termlib.KeyboardDisambiguate|termlib.KeyboardEventTypes)
+ */
+type KeyboardFlags uint8
+
+const (
+	KeyboardDisambiguate  KeyboardFlags = 1 << iota // report Esc, Alt+key and Ctrl+key unambiguously
+	KeyboardEventTypes                              // report repeats and releases
+	KeyboardAlternateKeys                           // report the Shifted and Base keys
+	KeyboardAllKeys                                 // report every key, including text, as an escape code
+	KeyboardText                                    // report the text a key produces
+)
+
+// queryTimeout is how long to wait for a terminal to answer a query.
+var queryTimeout = 200 * time.Millisecond
+
+/** EnableKittyKeyboard asks the terminal to report keys with the kitty
+ * keyboard protocol, which tells Ctrl+I from Tab and Ctrl+M from Enter,
+ * reports every modifier and can report key repeats and releases. The
+ * terminal is first asked whether it supports the protocol; if it does
+ * not answer within a short time, nothing is changed and keys keep
+ * arriving in the legacy encodings, which ReadKey and ReadEvent decode as
+ * before.
+ *
+ * in must be in raw mode and must not be read by anything else, such as a
+ * KeyReader, until this returns. Input typed meanwhile is kept for the
+ * next read. The protocol is turned off again by DisableKittyKeyboard,
+ * FullScreen or RestoreTerminal, and while the program is suspended.
+ *
+ * Parameters:
+ *   in    (io.Reader)     — terminal input in raw mode, typically os.Stdin.
+ *   flags (KeyboardFlags) — the enhancements wanted.
+ *
+ * Returns:
+ *   bool — true if the terminal supports the protocol and it is now on.
+ *
+ * Example:
+ *   if !term.EnableKittyKeyboard(os.Stdin, termlib.KeyboardDisambiguate) {
+ *       showHint("Ctrl+I is the same as Tab in this terminal")
+ *   }
+ */
+func (t *Terminal) EnableKittyKeyboard(in io.Reader, flags KeyboardFlags) bool {
+	if t.GetOutputMode() == OutputLines || t.Err() != nil {
+		return false
+	}
+	// Ask for the current flags, then for the primary device attributes,
+	// which every terminal answers: a terminal without the protocol
+	// answers only the second.
+	t.writeControl("\033[?u\033[c")
+	supported := false
+	dec := takeDecoder(in, true)
+	defer dec.put()
+	dec.awaitReply(queryTimeout, func(reply string) bool {
+		supported = strings.HasSuffix(reply, "u")
+		return true
+	})
+	if !supported {
+		return false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.write([]byte(t.kittyPopSeq()))
+	t.kittyFlags = flags
+	t.write([]byte(t.kittyPushSeq()))
+	t.trackModes()
+	return true
+}
+
+// DisableKittyKeyboard turns off the kitty keyboard protocol turned on by
+// EnableKittyKeyboard, returning to the legacy key encodings.
+func (t *Terminal) DisableKittyKeyboard() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.write([]byte(t.kittyPopSeq()))
+	t.kittyFlags = 0
+	t.trackModes()
+}
+
+// kittyPushSeq returns the sequence pushing the keyboard mode set by
+// EnableKittyKeyboard onto the terminal's stack of modes, if there is one.
+// t.mu must be held.
+func (t *Terminal) kittyPushSeq() string {
+	if t.kittyFlags == 0 {
+		return ""
+	}
+	return fmt.Sprintf("\033[>%du", t.kittyFlags)
+}
+
+// kittyPopSeq returns the sequence popping the keyboard mode pushed by
+// EnableKittyKeyboard, if there is one. t.mu must be held.
+func (t *Terminal) kittyPopSeq() string {
+	if t.kittyFlags == 0 {
+		return ""
+	}
+	return "\033[<u"
+}
+
+// decodeKittyKey decodes a kitty key report, ESC [ key:shifted:base ;
+// modifiers:action ; text u, given without its ESC. plain reports a key
+// that a legacy encoding would have sent as a single typed character.
+func decodeKittyKey(seq string) (ev KeyEvent, plain bool) {
+	fields := strings.Split(seq[1:len(seq)-1], ";")
+	codes := strings.Split(fields[0], ":")
+	code, err := strconv.Atoi(codes[0])
+	if err != nil {
+		return KeyEvent{Key: KeyUnknown}, false
+	}
+	ev.Key, plain = kittyKey(code)
+	if len(codes) > 1 {
+		ev.Shifted = kittyAltKey(codes[1])
+	}
+	if len(codes) > 2 {
+		ev.Base = kittyAltKey(codes[2])
+	}
+	if len(fields) > 1 {
+		mods := strings.Split(fields[1], ":")
+		ev.Mod = modifierParam(atoiOr(mods[0], 1))
+		if len(mods) > 1 {
+			ev.Action = kittyAction(mods[1])
+		}
+	}
+	return ev, plain && ev.Mod&^(ModShift|ModCtrl) == 0
+}
+
+// kittyKey maps a kitty key code to a Key. Codes outside the private use
+// area are Unicode characters; inside it, only the numeric keypad is
+// recognised. plain is false for Escape and keys without a character.
+func kittyKey(code int) (Key, bool) {
+	switch {
+	case code == 0x1b:
+		return Key(0x1b), false
+	case code >= 57399 && code <= 57427: // keypad
+		k := kittyKeypadKeys[code-57399]
+		return k, k < KeyUnknown
+	case code >= 0xe000 && code <= 0xf8ff: // other functional keys
+		return KeyUnknown, false
+	case code <= 0 || code > 0x10ffff:
+		return KeyUnknown, false
+	}
+	return Key(code), true
+}
+
+// kittyKeypadKeys are the keys of the numeric keypad, in the order of
+// their kitty key codes from 57399 (KP_0) to 57427 (KP_BEGIN).
+var kittyKeypadKeys = [...]Key{
+	'0', '1', '2', '3', '4', '5', '6', '7', '8', '9',
+	'.', '/', '*', '-', '+', '\r', '=', ',',
+	KeyLeft, KeyRight, KeyUp, KeyDown, KeyPageUp, KeyPageDown,
+	KeyHome, KeyEnd, KeyInsert, KeyDelete, KeyUnknown,
+}
+
+// kittyAltKey converts an alternate key code, which may be left empty, to
+// a Key, or to zero when there is none.
+func kittyAltKey(s string) Key {
+	if s == "" {
+		return 0
+	}
+	k, _ := kittyKey(atoiOr(s, 0))
+	return k
+}
+
+// splitKeyAction removes the kitty event type from the modifier parameter
+// of a functional key, so "[1;5:3A" becomes "[1;5A" with KeyRelease.
+func splitKeyAction(seq string) (string, KeyAction) {
+	i := strings.LastIndexByte(seq, ':')
+	if i < 0 || i < strings.LastIndexByte(seq, ';') {
+		return seq, KeyPress
+	}
+	final := seq[len(seq)-1:]
+	return seq[:i] + final, kittyAction(seq[i+1 : len(seq)-1])
+}
+
+// kittyAction converts a kitty event type to a KeyAction.
+func kittyAction(s string) KeyAction {
+	switch s {
+	case "2":
+		return KeyRepeat
+	case "3":
+		return KeyRelease
+	}
+	return KeyPress
+}
+
+// atoiOr returns s as a number, or def when s is not one.
+func atoiOr(s string, def int) int {
+	if n, err := strconv.Atoi(s); err == nil {
+		return n
+	}
+	return def
+}
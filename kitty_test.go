@@ -0,0 +1,110 @@
+// kitty_test.go — tests for the kitty keyboard protocol.
+// Copyright (C) 2025 R. S. Doiel
+package termlib
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestDecodeKittyKeys(t *testing.T) {
+	tests := []struct {
+		seq  string
+		want KeyEvent
+	}{
+		{"[105;5u", KeyEvent{Key: Key('i'), Mod: ModCtrl}},
+		{"[9u", KeyEvent{Key: Key('\t')}},
+		{"[13;2u", KeyEvent{Key: Key('\r'), Mod: ModShift}},
+		{"[27u", KeyEvent{Key: Key(0x1b)}},
+		{"[97;3:3u", KeyEvent{Key: Key('a'), Mod: ModAlt, Action: KeyRelease}},
+		{"[97:65;2u", KeyEvent{Key: Key('a'), Mod: ModShift, Shifted: Key('A')}},
+		{"[1089::99;5u", KeyEvent{Key: Key('с'), Mod: ModCtrl, Base: Key('c')}},
+		{"[97;69u", KeyEvent{Key: Key('a'), Mod: ModCtrl}}, // Caps Lock is dropped
+		{"[97;9u", KeyEvent{Key: Key('a'), Mod: ModMeta}},  // Super
+		{"[57414u", KeyEvent{Key: Key('\r')}},              // keypad Enter
+		{"[57419;5u", KeyEvent{Key: KeyUp, Mod: ModCtrl}},  // keypad Up
+		{"[57441u", KeyEvent{Key: KeyUnknown}},             // left Shift
+		{"[1;5:3A", KeyEvent{Key: KeyUp, Mod: ModCtrl, Action: KeyRelease}},
+		{"[3;1:2~", KeyEvent{Key: KeyDelete, Action: KeyRepeat}},
+		{"[13;1:1~", KeyEvent{Key: KeyF3}},
+	}
+	for _, tt := range tests {
+		ev, n, _ := decodeEvent([]byte("\033"+tt.seq), false)
+		if n != len(tt.seq)+1 || ev != tt.want {
+			t.Errorf("decodeEvent(%q) = %+v, %d; want %+v", tt.seq, ev, n, tt.want)
+		}
+	}
+}
+
+func TestReadKeyKitty(t *testing.T) {
+	in := pipeInput(t, "\033[105;5u\033[105;5:3u\033[97;2u\033[99;5u\033[1;5C")
+	for _, want := range []Key{Key('\t'), Key('A'), Key(0x03), KeyRight} {
+		k, err := ReadKey(in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if k != want {
+			t.Errorf("got %v, want %v", k, want)
+		}
+	}
+}
+
+func TestEnableKittyKeyboard(t *testing.T) {
+	resetRegistry(t)
+	var out bytes.Buffer
+	term := New(&out)
+	in := pipeInput(t, "x\033[?0u\033[?62;22c")
+	if !term.EnableKittyKeyboard(in, KeyboardDisambiguate|KeyboardEventTypes) {
+		t.Fatal("protocol not enabled")
+	}
+	if got := out.String(); got != "\033[?u\033[c\033[>3u" {
+		t.Errorf("enable: got %q", got)
+	}
+	// Input typed before the reply is kept; the late reply is not a key.
+	if k, err := ReadKey(in); k != Key('x') || err != nil {
+		t.Errorf("got %v, %v; want x", k, err)
+	}
+	if k, err := ReadKey(in); err != io.EOF {
+		t.Errorf("got %v, %v; want EOF", k, err)
+	}
+
+	out.Reset()
+	term.EnterAltScreen()
+	if got := out.String(); got != "\033[<u\033[?1049h\033[>3u" {
+		t.Errorf("enter alt screen: got %q", got)
+	}
+	out.Reset()
+	RestoreTerminal()
+	if got := out.String(); got != "\033[<u\033[?1049l" {
+		t.Errorf("restore: got %q", got)
+	}
+	if registered() != 0 {
+		t.Error("terminal still registered after restore")
+	}
+}
+
+func TestEnableKittyKeyboardFallback(t *testing.T) {
+	resetRegistry(t)
+	var out bytes.Buffer
+	term := New(&out)
+	if term.EnableKittyKeyboard(pipeInput(t, "\033[?62;22c"), KeyboardDisambiguate) {
+		t.Error("enabled without support")
+	}
+	if got := out.String(); got != "\033[?u\033[c" {
+		t.Errorf("got %q", got)
+	}
+
+	// No answer at all.
+	defer func(d time.Duration) { queryTimeout = d }(queryTimeout)
+	queryTimeout = 10 * time.Millisecond
+	r, w := io.Pipe()
+	defer w.Close()
+	if term.EnableKittyKeyboard(r, KeyboardDisambiguate) {
+		t.Error("enabled without an answer")
+	}
+	if registered() != 0 {
+		t.Error("terminal registered without a mode set")
+	}
+}
@@ -9,9 +9,7 @@ import (
 	"os"
 	"os/exec"
 	"strings"
-	"unicode/utf8"
-
-	"golang.org/x/term"
+	"sync"
 )
 
 // ErrInterrupted is returned by LineEditor.Prompt when the user presses Ctrl+C.
@@ -21,9 +19,16 @@ var ErrInterrupted = errors.New("interrupted")
  *
  * Supported keys:
  *   Left / Right arrows  — move cursor within the current line
+ *   Ctrl+Left / Ctrl+Right,
+ *   Alt+B / Alt+F        — move by word within the current line
  *   Home / End           — jump to start or end of line
  *   Up / Down arrows     — cycle through command history (only on first line)
  *   Backspace            — delete the character before the cursor
+ *   Delete               — delete the character under the cursor
+ *   Insert               — toggle overwrite mode, in which typed characters
+ *                          replace the one under the cursor; the cursor is
+ *                          a block while it is on, where the terminal
+ *                          supports cursor shapes
  *   Tab                  — complete the current word using Completer (if set);
  *                          first Tab lists all matches and fills the longest
  *                          common prefix; subsequent Tabs cycle through matches
@@ -32,6 +37,8 @@ var ErrInterrupted = errors.New("interrupted")
  *   Ctrl+J               — insert a newline for multi-line input; Enter submits
  *   Ctrl+K               — kill (delete) from cursor to end of current line
  *   Ctrl+C               — cancel input; returns ErrInterrupted
+ *   Ctrl+Z               — suspend the program (Unix, local terminals only);
+ *                          the line is redrawn when it is resumed
  *   Ctrl+D               — EOF on an empty buffer; delete character under cursor otherwise
  *   Ctrl+X Ctrl+E        — open $EDITOR (falling back to $VISUAL then vi) to
  *                          compose or edit the prompt; when the editor exits the
@@ -43,8 +50,22 @@ var ErrInterrupted = errors.New("interrupted")
  * navigation is disabled once the buffer contains a newline. Backspace across
  * a newline merges the current line back onto the previous one.
  *
- * When stdin is not a TTY (e.g. piped input in tests), Prompt falls back to
- * plain line reading without raw-mode terminal manipulation.
+ * Cursor motion and deletion work on whole user-perceived characters
+ * (grapheme clusters): a letter with combining accents, a flag, or an emoji
+ * joined with zero-width joiners moves and deletes as one unit, and the
+ * horizontal viewport is measured in display columns so wide CJK characters
+ * and emoji stay aligned.
+ *
+ * Pasted text is inserted literally: the terminal is put in bracketed paste
+ * mode while Prompt runs, so a pasted newline starts a new line as Ctrl+J
+ * does rather than submitting the input, and pasted tabs are not taken as
+ * completion requests.
+ *
+ * When the input has no TTY (e.g. piped input in tests), Prompt falls back to
+ * plain line reading without raw-mode terminal manipulation. Inputs that
+ * are not files, such as an SSH channel, get full line editing by setting
+ * the TTY field, and should be released with Close when the session ends.
+ * Ctrl+X Ctrl+E is only available when the input is a file.
  *
  * Example:
  *   le := termlib.NewLineEditor(os.Stdin, os.Stdout)
@@ -53,17 +74,21 @@ var ErrInterrupted = errors.New("interrupted")
  *   le.AppendHistory(line)
  */
 type LineEditor struct {
-	in        *os.File
+	in        io.Reader
+	dec       *Decoder // a file's shared Decoder, or one of its own
 	out       io.Writer
 	history   []string
-	histBuf   string              // draft saved while navigating history
+	histBuf   string                     // draft saved while navigating history
 	Completer func(line string) []string // optional; receives text up to cursor, returns word candidates
+	TTY       TTY                        // controls the terminal; nil falls back to plain line reading
+	caps      Capabilities               // of the terminal, from DetectCapabilities
 }
 
 /** NewLineEditor creates a LineEditor that reads from in and writes to out.
  *
  * Parameters:
- *   in  (*os.File)  — input file, typically os.Stdin.
+ *   in  (io.Reader) — input, typically os.Stdin; any reader works, such
+ *                     as an SSH channel (see TTY).
  *   out (io.Writer) — output destination, typically os.Stdout.
  *
  * Returns:
@@ -72,8 +97,25 @@ type LineEditor struct {
  * Example:
  *   le := termlib.NewLineEditor(os.Stdin, os.Stdout)
  */
-func NewLineEditor(in *os.File, out io.Writer) *LineEditor {
-	return &LineEditor{in: in, out: out}
+func NewLineEditor(in io.Reader, out io.Writer) *LineEditor {
+	return &LineEditor{in: in, out: out, dec: takeDecoder(in, true), TTY: ttyFor(in), caps: DetectCapabilities()}
+}
+
+/** Close releases what the LineEditor holds for reading in, when in is not
+ * a file: input typed ahead is discarded, and the goroutine reading ahead
+ * exits once the read it is waiting on returns. Call it when a session
+ * ends, however Prompt returned. in itself is not closed, and the
+ * LineEditor must not be used afterwards. For a file, whose input is
+ * shared with ReadKey and ReadEvent, Close does nothing.
+ *
+ * Example:
+ *   le := termlib.NewLineEditor(channel, channel)
+ *   defer le.Close()
+ */
+func (le *LineEditor) Close() {
+	if !le.dec.shared {
+		le.dec.Close()
+	}
 }
 
 /** AppendHistory adds line to the history list if it is non-empty and
@@ -135,11 +177,12 @@ func (le *LineEditor) History() []string {
 /** Prompt displays prompt, then reads and returns one edited line.
  * The terminal is placed in raw mode for the duration of the call and
  * restored before returning. If raw mode is unavailable (e.g. stdin is a
- * pipe) the call falls back to plain unbuffered line reading.
+ * pipe and TTY is nil) the call falls back to plain unbuffered line reading.
  *
  * Long lines scroll horizontally rather than wrapping: the display shows a
  * window over the buffer that pans to keep the cursor visible. Left/Right
- * arrows let the user navigate to any part of the line.
+ * arrows let the user navigate to any part of the line. The line is drawn
+ * again as soon as the window is resized.
  *
  * Parameters:
  *   prompt (string) — text printed before the cursor; must contain no ANSI
@@ -154,16 +197,27 @@ func (le *LineEditor) History() []string {
  *   line, err := le.Prompt("harvey > ")
  */
 func (le *LineEditor) Prompt(prompt string) (string, error) {
-	fd := int(le.in.Fd())
-	oldState, err := term.MakeRaw(fd)
+	if le.TTY == nil {
+		return le.fallback(prompt)
+	}
+	restore, err := makeRaw(le.TTY)
 	if err != nil {
 		return le.fallback(prompt)
 	}
-	defer term.Restore(fd, oldState)
-
-	termWidth, _, err := term.GetSize(fd)
-	if err != nil || termWidth <= 0 {
-		termWidth = 80
+	defer func() { restore() }()
+
+	// Bracketed paste lets pasted text be inserted literally instead of
+	// being run as keystrokes.
+	io.WriteString(le.out, "\033[?2004h")
+	defer io.WriteString(le.out, "\033[?2004l")
+
+	// The width is re-read before every redraw, and a resize redraws at
+	// once, so the viewport always matches the current terminal.
+	termWidth := 80
+	readWidth := func() {
+		if w, _, err := le.TTY.Size(); err == nil && w > 0 {
+			termWidth = w
+		}
 	}
 
 	const contPrompt = "...  " // shown on lines 2+ of multi-line input
@@ -172,9 +226,9 @@ func (le *LineEditor) Prompt(prompt string) (string, error) {
 
 	buf := []rune{}
 	pos := 0
-	viewOffset := 0        // horizontal scroll offset, relative to current line's start
+	viewOffset := 0 // horizontal scroll offset, relative to current line's start
 	histIdx := len(le.history)
-	lineCount := 0         // number of '\n' characters currently in buf
+	lineCount := 0 // number of '\n' characters currently in buf
 
 	// currentLineStart returns the buf index where the current visual line begins
 	// (one past the last '\n' before pos, or 0 if none).
@@ -189,7 +243,10 @@ func (le *LineEditor) Prompt(prompt string) (string, error) {
 
 	// redraw repaints only the current visual line. Previous lines are frozen on
 	// screen above. The horizontal viewport pans automatically to keep pos in view.
+	// The viewport is measured in display columns and always starts and ends on
+	// a grapheme cluster boundary, so wide and combined characters are never split.
 	redraw := func() {
+		readWidth()
 		lineStart := currentLineStart()
 
 		// Find end of current line (stop at the next '\n', if any).
@@ -205,38 +262,58 @@ func (le *LineEditor) Prompt(prompt string) (string, error) {
 		if lineCount > 0 {
 			curPrompt = contPrompt
 		}
-		curPromptLen := utf8.RuneCountInString(curPrompt)
-		vw := termWidth - curPromptLen
+		vw := termWidth - StringWidth(curPrompt)
 		if vw < 1 {
 			vw = 1
 		}
 
-		localPos := pos - lineStart // cursor position within the current line
-
-		// Pan viewport to keep cursor visible.
-		if localPos < viewOffset {
-			viewOffset = localPos
-		} else if localPos >= viewOffset+vw {
-			viewOffset = localPos - vw + 1
-		}
-
+		// Pan viewport to keep cursor visible: the cursor column, measured
+		// from the start of the viewport, must stay inside the viewport.
 		dispStart := lineStart + viewOffset
-		dispEnd := dispStart + vw
-		if dispEnd > lineEnd {
-			dispEnd = lineEnd
+		if dispStart > pos {
+			dispStart = pos
+		} else if dispStart > lineStart {
+			// Edits before the viewport can leave it mid-cluster.
+			dispStart = lePrevBoundary(buf, dispStart+1)
+		}
+		for dispStart < pos && runesWidth(buf[dispStart:pos]) >= vw {
+			dispStart = leNextBoundary(buf, dispStart)
+		}
+		// After the window widens, take back text scrolled off to the left.
+		for dispStart > lineStart {
+			prev := lePrevBoundary(buf, dispStart)
+			if runesWidth(buf[prev:pos]) >= vw || runesWidth(buf[prev:lineEnd]) >= vw {
+				break
+			}
+			dispStart = prev
+		}
+		viewOffset = dispStart - lineStart
+
+		// Show whole clusters from dispStart for as long as they fit.
+		dispEnd, used := dispStart, 0
+		for dispEnd < lineEnd {
+			next := leNextBoundary(buf, dispEnd)
+			cw := runesWidth(buf[dispEnd:next])
+			if used+cw > vw {
+				break
+			}
+			used += cw
+			dispEnd = next
 		}
 
 		io.WriteString(le.out, "\r")
 		io.WriteString(le.out, curPrompt)
 		io.WriteString(le.out, string(buf[dispStart:dispEnd]))
 		io.WriteString(le.out, "\033[K") // clear to end of line
-		if back := dispEnd - pos; back > 0 {
-			fmt.Fprintf(le.out, "\033[%dD", back)
+		if pos < dispEnd {
+			fmt.Fprintf(le.out, "\033[%dD", runesWidth(buf[pos:dispEnd]))
 		}
 	}
 
-	b := make([]byte, 1)
 	ctrlXPending := false
+	overwrite := false // typed characters replace the one under the cursor
+	cursor := leCursor{out: le.out, enabled: le.caps.CursorStyle}
+	defer cursor.setBlock(false)
 
 	// Tab completion state — reset whenever a non-Tab key is pressed.
 	var tabMatches []string
@@ -244,26 +321,70 @@ func (le *LineEditor) Prompt(prompt string) (string, error) {
 	var tabIdx int       // next match index for cycling
 	lastWasTab := false
 
+	resize := watchLeResize(le.TTY)
+	defer resize.stop()
+
 	for {
-		if _, err := le.in.Read(b); err != nil {
+		stop, resized := resize.wait()
+		if resized {
+			redraw()
+		}
+		ev, plain, err := le.dec.nextUntil(stop)
+		if err == errStopped {
+			continue
+		}
+		if err != nil {
 			return string(buf), err
 		}
-		ch := b[0]
+		var ch Key
+		var mod Modifier
+		switch ev := ev.(type) {
+		case KeyEvent:
+			if ev.Action == KeyRelease {
+				continue
+			}
+			ch, mod = ev.Key, ev.Mod
+			if plain {
+				ch = ev.legacyKey() // kitty protocol: Ctrl+A is Key('a') with ModCtrl
+			}
+		case PasteEvent: // Bracketed paste — insert the text literally
+			ctrlXPending, lastWasTab = false, false
+			for i, line := range strings.Split(lePasteText(ev.Text), "\n") {
+				if i > 0 {
+					// Each pasted newline starts a new line, as with Ctrl+J.
+					redraw()
+					buf = leInsertRune(buf, pos, '\n')
+					pos++
+					lineCount++
+					viewOffset = 0
+					io.WriteString(le.out, "\r\n")
+				}
+				for _, r := range line {
+					buf = leInsertRune(buf, pos, r)
+					pos++
+				}
+			}
+			redraw()
+			continue
+		default: // mouse and focus reports are not used
+			continue
+		}
 
 		// Ctrl+X chord: wait for the second key.
 		if ctrlXPending {
 			ctrlXPending = false
 			lastWasTab = false
-			if ch == 0x05 { // Ctrl+E — open $EDITOR
-				term.Restore(fd, oldState)
+			if _, isFile := le.in.(*os.File); isFile && plain && ch == 0x05 { // Ctrl+E — open $EDITOR
+				restore()
 				result, edErr := le.openEditor(buf)
 				if edErr == nil {
 					io.WriteString(le.out, "\r\n")
 					return result, nil
 				}
 				// Editor failed — re-enter raw mode and continue editing.
-				if _, merr := term.MakeRaw(fd); merr != nil {
-					return string(buf), merr
+				if restore, err = makeRaw(le.TTY); err != nil {
+					restore = func() {}
+					return string(buf), err
 				}
 				fmt.Fprintf(le.out, "\r\n  (editor: %v)\r\n", edErr)
 				io.WriteString(le.out, prompt)
@@ -345,9 +466,10 @@ func (le *LineEditor) Prompt(prompt string) (string, error) {
 				io.WriteString(le.out, "\r\n")
 				return "", io.EOF
 			}
-			// Don't delete across a newline boundary.
+			// Delete the whole character under the cursor, but not across a
+			// newline boundary.
 			if pos < len(buf) && buf[pos] != '\n' {
-				buf = append(buf[:pos], buf[pos+1:]...)
+				buf = append(buf[:pos], buf[leNextBoundary(buf, pos):]...)
 				redraw()
 			}
 
@@ -379,6 +501,15 @@ func (le *LineEditor) Prompt(prompt string) (string, error) {
 			buf = append(buf[:pos], buf[killEnd:]...)
 			redraw()
 
+		case ch == 0x1a: // Ctrl+Z — suspend; the line is redrawn on resume
+			if _, local := le.TTY.(fdTTY); !local || suspendCheck() != nil {
+				break // never stop a server for a remote session's Ctrl+Z
+			}
+			io.WriteString(le.out, "\033[?2004l\r\n")
+			Suspend()
+			io.WriteString(le.out, "\033[?2004h")
+			redraw()
+
 		case ch == 0x18: // Ctrl+X — first key of a two-key chord
 			ctrlXPending = true
 
@@ -395,15 +526,19 @@ func (le *LineEditor) Prompt(prompt string) (string, error) {
 					viewOffset = 0
 					redraw()
 				} else {
-					buf = append(buf[:pos-1], buf[pos:]...)
-					pos--
+					// Remove the whole character before the cursor, including
+					// any combining marks or joined emoji.
+					prev := lePrevBoundary(buf, pos)
+					buf = append(buf[:prev], buf[pos:]...)
+					pos = prev
 					redraw()
 				}
 			}
 
-		case ch == 0x1b: // Escape — consume the rest of the sequence
-			switch seq := le.readEscSeq(); seq {
-			case "[A", "OA": // Up arrow — history previous (disabled in multi-line mode)
+		case !plain: // Escape sequence keys: arrows, Home/End, Delete, Alt+key
+			word := mod&(ModCtrl|ModAlt) != 0
+			switch {
+			case ch == KeyUp: // Up arrow — history previous (disabled in multi-line mode)
 				if lineCount == 0 && histIdx > 0 {
 					if histIdx == len(le.history) {
 						le.histBuf = string(buf) // save current draft
@@ -416,7 +551,7 @@ func (le *LineEditor) Prompt(prompt string) (string, error) {
 					lineCount = strings.Count(string(buf), "\n")
 					redraw()
 				}
-			case "[B", "OB": // Down arrow — history next (disabled in multi-line mode)
+			case ch == KeyDown: // Down arrow — history next (disabled in multi-line mode)
 				if lineCount == 0 && histIdx < len(le.history) {
 					histIdx++
 					if histIdx == len(le.history) {
@@ -429,21 +564,37 @@ func (le *LineEditor) Prompt(prompt string) (string, error) {
 					lineCount = strings.Count(string(buf), "\n")
 					redraw()
 				}
-			case "[C", "OC": // Right arrow — stay within current line
+			case word && ch == KeyRight, ch == Key('f') && mod == ModAlt:
+				// Ctrl+Right / Alt+Right / Alt+F — end of the next word
+				pos = leWordRight(buf, pos)
+				redraw()
+			case word && ch == KeyLeft, ch == Key('b') && mod == ModAlt:
+				// Ctrl+Left / Alt+Left / Alt+B — start of the previous word
+				pos = leWordLeft(buf, pos)
+				redraw()
+			case ch == KeyRight: // Right arrow — stay within current line
 				if pos < len(buf) && buf[pos] != '\n' {
-					pos++
+					pos = leNextBoundary(buf, pos)
 					redraw()
 				}
-			case "[D", "OD": // Left arrow — stay within current line
+			case ch == KeyLeft: // Left arrow — stay within current line
 				if pos > 0 && buf[pos-1] != '\n' {
-					pos--
+					pos = lePrevBoundary(buf, pos)
 					redraw()
 				}
-			case "[H", "OH", "[1~": // Home — beginning of current line
+			case ch == KeyInsert: // Insert — toggle overwrite mode, shown by a block cursor
+				overwrite = !overwrite
+				cursor.setBlock(overwrite)
+			case ch == KeyDelete: // Delete — delete character under cursor, not past '\n'
+				if pos < len(buf) && buf[pos] != '\n' {
+					buf = append(buf[:pos], buf[leNextBoundary(buf, pos):]...)
+					redraw()
+				}
+			case ch == KeyHome: // Home — beginning of current line
 				pos = currentLineStart()
 				viewOffset = 0
 				redraw()
-			case "[F", "OF", "[4~": // End — end of current line
+			case ch == KeyEnd: // End — end of current line
 				lineStart := currentLineStart()
 				lineEnd := len(buf)
 				for i := lineStart; i < len(buf); i++ {
@@ -456,17 +607,14 @@ func (le *LineEditor) Prompt(prompt string) (string, error) {
 				redraw()
 			}
 
-		case ch >= 0x20 && ch < 0x7f: // Printable ASCII
+		case ch >= 0x20 && ch != 0x7f: // Printable character
+			if overwrite && pos < len(buf) && buf[pos] != '\n' {
+				// Replace the whole character under the cursor.
+				buf = append(buf[:pos], buf[leNextBoundary(buf, pos):]...)
+			}
 			buf = leInsertRune(buf, pos, rune(ch))
 			pos++
 			redraw()
-
-		case ch >= 0xc0: // UTF-8 multi-byte lead byte
-			if r := le.readUTF8Tail(ch); r != utf8.RuneError {
-				buf = leInsertRune(buf, pos, r)
-				pos++
-				redraw()
-			}
 		}
 	}
 }
@@ -519,57 +667,6 @@ func (le *LineEditor) openEditor(buf []rune) (string, error) {
 	return strings.TrimRight(string(data), "\r\n"), nil
 }
 
-// readEscSeq reads the bytes that follow an ESC character and returns a
-// short string identifying the sequence, e.g. "[A" for up-arrow.
-// It handles both CSI (\x1b[…) and SS3 (\x1bO…) forms.
-func (le *LineEditor) readEscSeq() string {
-	b := make([]byte, 1)
-	if _, err := le.in.Read(b); err != nil {
-		return ""
-	}
-	switch b[0] {
-	case '[': // CSI sequence
-		var seq []byte
-		for {
-			if _, err := le.in.Read(b); err != nil {
-				break
-			}
-			seq = append(seq, b[0])
-			// Sequences terminate on a letter or '~'
-			if (b[0] >= 'A' && b[0] <= 'Z') || (b[0] >= 'a' && b[0] <= 'z') || b[0] == '~' {
-				break
-			}
-		}
-		return "[" + string(seq)
-	case 'O': // SS3 sequence (application cursor keys)
-		if _, err := le.in.Read(b); err != nil {
-			return ""
-		}
-		return "O" + string(b[:1])
-	default:
-		return ""
-	}
-}
-
-// readUTF8Tail reads the continuation bytes for a multi-byte UTF-8
-// sequence whose leading byte is lead, and returns the decoded rune.
-func (le *LineEditor) readUTF8Tail(lead byte) rune {
-	var tailLen int
-	switch {
-	case lead >= 0xf0:
-		tailLen = 3
-	case lead >= 0xe0:
-		tailLen = 2
-	default:
-		tailLen = 1
-	}
-	tail := make([]byte, tailLen)
-	le.in.Read(tail)
-	full := append([]byte{lead}, tail...)
-	r, _ := utf8.DecodeRune(full)
-	return r
-}
-
 // fallback reads a plain line without raw-mode manipulation. Used when
 // stdin is not a TTY (e.g. during tests with piped input).
 func (le *LineEditor) fallback(prompt string) (string, error) {
@@ -588,6 +685,81 @@ func (le *LineEditor) fallback(prompt string) (string, error) {
 	}
 }
 
+// leResize tells Prompt when the window has been resized, by closing the
+// channel it waits for input with.
+type leResize struct {
+	mu      sync.Mutex
+	resized chan struct{}
+	stop    func()
+}
+
+// watchLeResize starts watching tty for a change of width.
+func watchLeResize(tty TTY) *leResize {
+	r := &leResize{resized: make(chan struct{})}
+	sig, stopSig := notifyResize()
+	done := make(chan struct{})
+	width, _, _ := tty.Size()
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-sig:
+				if w, _, err := tty.Size(); err == nil && w != width {
+					width = w
+					r.mu.Lock()
+					if !isClosed(r.resized) {
+						close(r.resized)
+					}
+					r.mu.Unlock()
+				}
+			}
+		}
+	}()
+	r.stop = func() {
+		stopSig()
+		close(done)
+	}
+	return r
+}
+
+// wait returns the channel to wait for input with, and whether the window
+// has been resized since the last call.
+func (r *leResize) wait() (<-chan struct{}, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !isClosed(r.resized) {
+		return r.resized, false
+	}
+	r.resized = make(chan struct{})
+	return r.resized, true
+}
+
+// leCursor shows the block cursor of overwrite mode on terminals that can
+// change the cursor shape. While shown it is registered with
+// RestoreTerminal and Suspend, so that the shell never inherits it.
+type leCursor struct {
+	out     io.Writer
+	enabled bool
+	entry   *restorer
+}
+
+// setBlock shows the block cursor, or the terminal's own cursor again.
+func (c *leCursor) setBlock(block bool) {
+	show := func(s CursorShape) func() {
+		return func() { io.WriteString(c.out, s.seq()) }
+	}
+	switch {
+	case block && c.enabled && c.entry == nil:
+		show(CursorSteadyBlock)()
+		c.entry = registerRestore(show(CursorDefault), show(CursorDefault), show(CursorSteadyBlock))
+	case !block && c.entry != nil:
+		show(CursorDefault)()
+		c.entry.unregister()
+		c.entry = nil
+	}
+}
+
 // leInsertRune inserts r into buf at position pos and returns the new slice.
 func leInsertRune(buf []rune, pos int, r rune) []rune {
 	buf = append(buf, 0)
@@ -596,6 +768,75 @@ func leInsertRune(buf []rune, pos int, r rune) []rune {
 	return buf
 }
 
+// leNextBoundary returns the index of the first grapheme cluster boundary
+// in buf after pos, or len(buf) when pos is in the last cluster.
+func leNextBoundary(buf []rune, pos int) int {
+	if pos >= len(buf) {
+		return len(buf)
+	}
+	return pos + graphemeLen(buf[pos:])
+}
+
+// lePrevBoundary returns the index of the last grapheme cluster boundary in
+// buf before pos, or 0 when there is none. Scanning starts after the
+// closest preceding newline, which is always a boundary.
+func lePrevBoundary(buf []rune, pos int) int {
+	start := 0
+	for i := pos - 1; i > 0; i-- {
+		if buf[i-1] == '\n' {
+			start = i
+			break
+		}
+	}
+	prev := start
+	for i := start; i < pos; i = leNextBoundary(buf, i) {
+		prev = i
+	}
+	return prev
+}
+
+// lePasteText prepares pasted text for insertion into the buffer: line
+// endings become '\n' and control characters other than tab are dropped.
+func lePasteText(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+	return strings.Map(func(r rune) rune {
+		if (r < 0x20 && r != '\n' && r != '\t') || r == 0x7f {
+			return -1
+		}
+		return r
+	}, text)
+}
+
+// leWordLeft returns the start of the word before pos, skipping any spaces
+// immediately before pos. It does not move past the start of the line.
+func leWordLeft(buf []rune, pos int) int {
+	for pos > 0 && leIsSpace(buf[pos-1]) {
+		pos--
+	}
+	for pos > 0 && !leIsSpace(buf[pos-1]) && buf[pos-1] != '\n' {
+		pos--
+	}
+	return pos
+}
+
+// leWordRight returns the end of the word after pos, skipping any spaces
+// at pos. It does not move past the end of the line.
+func leWordRight(buf []rune, pos int) int {
+	for pos < len(buf) && leIsSpace(buf[pos]) {
+		pos++
+	}
+	for pos < len(buf) && !leIsSpace(buf[pos]) && buf[pos] != '\n' {
+		pos++
+	}
+	return pos
+}
+
+// leIsSpace reports whether r separates words for word-wise motion.
+func leIsSpace(r rune) bool {
+	return r == ' ' || r == '\t'
+}
+
 // leCommonPrefix returns the longest string that is a prefix of every element
 // of strs. Returns "" when strs is empty.
 func leCommonPrefix(strs []string) string {
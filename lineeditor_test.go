@@ -253,28 +253,28 @@ func TestLeInsertRune_doesNotMutateOriginal(t *testing.T) {
 	}
 }
 
-// ─── readEscSeq ──────────────────────────────────────────────────────────────
+// ─── input decoding ──────────────────────────────────────────────────────────
 
-func TestReadEscSeq_arrows(t *testing.T) {
+func TestLineEditorDecodesEscSeq(t *testing.T) {
 	tests := []struct {
 		name  string
 		input string
-		want  string
+		want  Key
 	}{
-		{"up arrow CSI",    "[A", "[A"},
-		{"down arrow CSI",  "[B", "[B"},
-		{"right arrow CSI", "[C", "[C"},
-		{"left arrow CSI",  "[D", "[D"},
-		{"home CSI",        "[H", "[H"},
-		{"end CSI",         "[F", "[F"},
-		{"home VT220",      "[1~", "[1~"},
-		{"end VT220",       "[4~", "[4~"},
-		{"up SS3",          "OA", "OA"},
-		{"down SS3",        "OB", "OB"},
-		{"right SS3",       "OC", "OC"},
-		{"left SS3",        "OD", "OD"},
-		{"home SS3",        "OH", "OH"},
-		{"end SS3",         "OF", "OF"},
+		{"up arrow CSI", "[A", KeyUp},
+		{"down arrow CSI", "[B", KeyDown},
+		{"right arrow CSI", "[C", KeyRight},
+		{"left arrow CSI", "[D", KeyLeft},
+		{"home CSI", "[H", KeyHome},
+		{"end CSI", "[F", KeyEnd},
+		{"home VT220", "[1~", KeyHome},
+		{"end VT220", "[4~", KeyEnd},
+		{"up SS3", "OA", KeyUp},
+		{"down SS3", "OB", KeyDown},
+		{"right SS3", "OC", KeyRight},
+		{"left SS3", "OD", KeyLeft},
+		{"home SS3", "OH", KeyHome},
+		{"end SS3", "OF", KeyEnd},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -283,36 +283,44 @@ func TestReadEscSeq_arrows(t *testing.T) {
 				t.Fatalf("os.Pipe: %v", err)
 			}
 			defer r.Close()
-			w.WriteString(tt.input)
+			w.WriteString("\x1b" + tt.input)
 			w.Close()
 
 			le := NewLineEditor(r, io.Discard)
-			got := le.readEscSeq()
-			if got != tt.want {
-				t.Errorf("want %q, got %q", tt.want, got)
+			ev, plain, err := le.dec.next()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := ev.(KeyEvent).Key; got != tt.want || plain {
+				t.Errorf("want %v, got %v (plain %v)", tt.want, got, plain)
 			}
 		})
 	}
 }
 
-func TestReadEscSeq_emptyOnEOF(t *testing.T) {
+func TestLineEditorBareEscOnEOF(t *testing.T) {
 	r, w, err := os.Pipe()
 	if err != nil {
 		t.Fatalf("os.Pipe: %v", err)
 	}
 	defer r.Close()
-	w.Close() // immediate EOF
+	w.WriteString("\x1b")
+	w.Close() // EOF straight after the ESC
 
 	le := NewLineEditor(r, io.Discard)
-	got := le.readEscSeq()
-	if got != "" {
-		t.Errorf("want empty string on EOF, got %q", got)
+	ev, _, err := le.dec.next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ev.(KeyEvent).Key; got != Key(0x1b) {
+		t.Errorf("want bare ESC, got %v", got)
+	}
+	if _, _, err := le.dec.next(); err != io.EOF {
+		t.Errorf("want io.EOF, got %v", err)
 	}
 }
 
-// ─── readUTF8Tail ────────────────────────────────────────────────────────────
-
-func TestReadUTF8Tail(t *testing.T) {
+func TestLineEditorDecodesUTF8(t *testing.T) {
 	tests := []struct {
 		name  string
 		input rune // the rune to encode and round-trip
@@ -325,27 +333,219 @@ func TestReadUTF8Tail(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Encode the rune to UTF-8, split into lead byte + tail bytes.
+			// Encode the rune to UTF-8 and deliver the lead byte and the
+			// tail bytes in separate writes.
 			encoded := []byte(string(tt.input))
 			if len(encoded) < 2 {
 				t.Fatalf("rune %q encoded as single byte, not multi-byte", tt.input)
 			}
-			lead := encoded[0]
-			tail := encoded[1:]
 
 			r, w, err := os.Pipe()
 			if err != nil {
 				t.Fatalf("os.Pipe: %v", err)
 			}
 			defer r.Close()
-			w.Write(tail)
+			le := NewLineEditor(r, io.Discard)
+			w.Write(encoded[:1])
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				ev, plain, err := le.dec.next()
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if got := rune(ev.(KeyEvent).Key); got != tt.input || !plain {
+					t.Errorf("want %q (%U), got %q (%U)", tt.input, tt.input, got, got)
+				}
+			}()
+			w.Write(encoded[1:])
 			w.Close()
+			<-done
+		})
+	}
+}
 
-			le := NewLineEditor(r, io.Discard)
-			got := le.readUTF8Tail(lead)
-			if got != tt.input {
-				t.Errorf("want %q (%U), got %q (%U)", tt.input, tt.input, got, got)
+// ─── grapheme boundaries ─────────────────────────────────────────────────────
+
+func TestLeBoundaries(t *testing.T) {
+	tests := []struct {
+		name string
+		buf  string
+		pos  int // rune index
+		next int
+		prev int
+	}{
+		{"ascii", "abc", 1, 2, 0},
+		{"combining mark", "ae\u0301b", 1, 3, 0},
+		{"after combining mark", "ae\u0301b", 3, 4, 1},
+		{"vietnamese stacked marks", "Vie\u0323\u0302t", 5, 6, 2},
+		{"devanagari", "नमस्ते", 2, 4, 1},
+		{"flag", "a🇯🇵b", 3, 4, 1},
+		{"zwj sequence", "👨‍👩‍👧x", 5, 6, 0},
+		{"newline is a boundary", "ab\ncd", 4, 5, 3},
+		{"end of buffer", "ab", 2, 2, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := []rune(tt.buf)
+			if got := leNextBoundary(buf, tt.pos); got != tt.next {
+				t.Errorf("leNextBoundary(%q, %d) = %d, want %d", tt.buf, tt.pos, got, tt.next)
+			}
+			if got := lePrevBoundary(buf, tt.pos); got != tt.prev {
+				t.Errorf("lePrevBoundary(%q, %d) = %d, want %d", tt.buf, tt.pos, got, tt.prev)
 			}
 		})
 	}
 }
+
+func TestLeWordMotion(t *testing.T) {
+	buf := []rune("git  commit -m\nnext line")
+	if got := leWordLeft(buf, 11); got != 5 {
+		t.Errorf("leWordLeft from end of commit: got %d, want 5", got)
+	}
+	if got := leWordLeft(buf, 5); got != 0 {
+		t.Errorf("leWordLeft across spaces: got %d, want 0", got)
+	}
+	if got := leWordRight(buf, 3); got != 11 {
+		t.Errorf("leWordRight across spaces: got %d, want 11", got)
+	}
+	if got := leWordRight(buf, 12); got != 14 {
+		t.Errorf("leWordRight stops at newline: got %d, want 14", got)
+	}
+	if got := leWordLeft(buf, 15); got != 15 {
+		t.Errorf("leWordLeft stops at line start: got %d, want 15", got)
+	}
+}
+
+func TestLePasteText(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"plain", "plain"},
+		{"one\r\ntwo\rthree\nfour", "one\ntwo\nthree\nfour"},
+		{"a\tb", "a\tb"},
+		{"x\x03y\x1b\x7fz", "xyz"},
+	}
+	for _, tt := range tests {
+		if got := lePasteText(tt.in); got != tt.want {
+			t.Errorf("lePasteText(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// fakeTTY is a TTY for tests driving LineEditor through a plain reader.
+type fakeTTY struct{ width int }
+
+func (f fakeTTY) MakeRaw() (func() error, error) { return func() error { return nil }, nil }
+func (f fakeTTY) Size() (int, int, error)        { return f.width, 24, nil }
+
+func TestPrompt_readerWithTTY(t *testing.T) {
+	// Type "ab", move left, insert "c", paste a tab, then press Enter.
+	in := strings.NewReader("ab\x1b[Dc\x1b[200~\t\x1b[201~\r")
+	var out strings.Builder
+	le := NewLineEditor(in, &out)
+	if le.TTY != nil {
+		t.Fatal("strings.Reader should have no TTY")
+	}
+	le.TTY = fakeTTY{width: 40}
+	got, err := le.Prompt("> ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "ac\tb" {
+		t.Errorf("want %q, got %q", "ac\tb", got)
+	}
+	if !strings.Contains(out.String(), "\x1b[?2004h") {
+		t.Errorf("bracketed paste not enabled: %q", out.String())
+	}
+}
+
+func TestLineEditorClose(t *testing.T) {
+	// A session abandoned with Ctrl+C, its input still open.
+	r, w := io.Pipe()
+	defer w.Close()
+	le := NewLineEditor(r, io.Discard)
+	le.TTY = fakeTTY{width: 40}
+	go w.Write([]byte("ab\x03"))
+	if _, err := le.Prompt("> "); err != ErrInterrupted {
+		t.Fatalf("want ErrInterrupted, got %v", err)
+	}
+	le.Close()
+	if sharedDecoder(r) {
+		t.Error("decoder kept after Close")
+	}
+	// The read left waiting takes this and exits instead of keeping it.
+	w.Write([]byte("c"))
+	if sharedDecoder(r) {
+		t.Error("decoder kept after Close")
+	}
+	if _, _, err := le.dec.next(); err != os.ErrClosed {
+		t.Errorf("want os.ErrClosed, got %v", err)
+	}
+}
+
+func TestPrompt_overwrite(t *testing.T) {
+	// Type "abc", go Home, toggle overwrite with Insert and type "xy".
+	in := strings.NewReader("abc\x1b[H\x1b[2~xy\r")
+	var out strings.Builder
+	le := NewLineEditor(in, &out)
+	le.TTY = fakeTTY{width: 40}
+	got, err := le.Prompt("> ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "xyc" {
+		t.Errorf("want %q, got %q", "xyc", got)
+	}
+	s := out.String()
+	if !strings.Contains(s, "\x1b[2 q") || !strings.Contains(s, "\r\n\x1b[0 q") {
+		t.Errorf("block cursor not shown and reset: %q", s)
+	}
+}
+
+func TestPrompt_overwriteWithoutCursorStyle(t *testing.T) {
+	in := strings.NewReader("ab\x1b[D\x1b[2~x\r")
+	var out strings.Builder
+	le := NewLineEditor(in, &out)
+	le.TTY = fakeTTY{width: 40}
+	le.caps.CursorStyle = false
+	got, err := le.Prompt("> ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "ax" {
+		t.Errorf("want %q, got %q", "ax", got)
+	}
+	if s := out.String(); strings.Contains(s, " q") {
+		t.Errorf("cursor shape sent: %q", s)
+	}
+}
+
+func TestLeCursorRestored(t *testing.T) {
+	resetRegistry(t)
+	var out strings.Builder
+	c := leCursor{out: &out, enabled: true}
+	c.setBlock(true)
+	if registered() != 1 {
+		t.Fatal("block cursor not registered")
+	}
+	RestoreTerminal()
+	if got := out.String(); got != "\x1b[2 q\x1b[0 q" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestPrompt_kittyKeys(t *testing.T) {
+	// Type "ab", press Ctrl+A (Home) and "x" with key releases reported,
+	// then Enter, all in the kitty keyboard protocol.
+	in := strings.NewReader("\x1b[97u\x1b[98u\x1b[97;5u\x1b[97;5:3u\x1b[120u\x1b[120;1:3u\x1b[13u")
+	var out strings.Builder
+	le := NewLineEditor(in, &out)
+	le.TTY = fakeTTY{width: 40}
+	got, err := le.Prompt("> ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "xab" {
+		t.Errorf("want %q, got %q", "xab", got)
+	}
+}
@@ -0,0 +1,85 @@
+//go:build !windows
+
+// lineeditor_unix_test.go — tests for LineEditor that send signals.
+// Copyright (C) 2025 R. S. Doiel
+package termlib
+
+import (
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// resizingTTY is a TTY whose width a test can change.
+type resizingTTY struct{ width atomic.Int32 }
+
+func (r *resizingTTY) MakeRaw() (func() error, error) { return func() error { return nil }, nil }
+func (r *resizingTTY) Size() (int, int, error)        { return int(r.width.Load()), 24, nil }
+
+// lockedBuilder is a strings.Builder safe to write and read concurrently.
+type lockedBuilder struct {
+	mu sync.Mutex
+	b  strings.Builder
+}
+
+func (l *lockedBuilder) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.b.Write(p)
+}
+
+func (l *lockedBuilder) String() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.b.String()
+}
+
+// waitFor waits until cond holds, failing the test after a few seconds.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestPrompt_redrawsOnResize(t *testing.T) {
+	r, w := io.Pipe()
+	defer w.Close()
+	tty := &resizingTTY{}
+	tty.width.Store(10)
+	var out lockedBuilder
+	le := NewLineEditor(r, &out)
+	defer le.Close()
+	le.TTY = tty
+	done := make(chan error, 1)
+	go func() {
+		_, err := le.Prompt("> ")
+		done <- err
+	}()
+
+	// At 10 columns only the end of the line fits beside the prompt.
+	w.Write([]byte("abcdefghijkl"))
+	waitFor(t, "the line to be drawn", func() bool {
+		return strings.HasSuffix(out.String(), "\r> fghijkl\x1b[K")
+	})
+
+	// Widening the window shows the whole line with no key pressed.
+	tty.width.Store(40)
+	syscall.Kill(syscall.Getpid(), syscall.SIGWINCH)
+	waitFor(t, "the line to be redrawn", func() bool {
+		return strings.HasSuffix(out.String(), "\r> abcdefghijkl\x1b[K")
+	})
+
+	w.Write([]byte("\r"))
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
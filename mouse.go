@@ -0,0 +1,189 @@
+// mouse.go — mouse reporting modes and mouse report decoding.
+// Copyright (C) 2025 R. S. Doiel
+package termlib
+
+import (
+	"strconv"
+	"strings"
+)
+
+// MouseButton identifies the mouse button or wheel direction of a
+// MouseEvent.
+type MouseButton int
+
+const (
+	MouseNone       MouseButton = iota // no button, e.g. plain motion
+	MouseLeft                          // primary button
+	MouseMiddle                        // middle button or wheel click
+	MouseRight                         // secondary button
+	MouseWheelUp                       // wheel scrolled up (away from the user)
+	MouseWheelDown                     // wheel scrolled down
+	MouseWheelLeft                     // wheel tilted or scrolled left
+	MouseWheelRight                    // wheel tilted or scrolled right
+)
+
+// MouseAction is what happened to the button of a MouseEvent.
+type MouseAction int
+
+const (
+	MousePress   MouseAction = iota // button pressed or wheel scrolled
+	MouseRelease                    // button released
+	MouseMotion                     // pointer moved, possibly with a button held
+)
+
+/** MouseEvent reports a mouse action at column X, row Y (both 1-based, as
+ * used by Terminal.Move). Mouse reporting must be enabled with
+ * Terminal.EnableMouse for these to be sent. Wheel movement is reported
+ * as a MousePress of one of the wheel buttons.
+ *
+ * Only Shift, Alt and Ctrl are reported in Mod, and many terminals keep
+ * some of these combinations for themselves (Shift+click commonly selects
+ * text instead of being reported).
+ *
+ * Example:
+ *   case termlib.MouseEvent:
+ *       if ev.Button == termlib.MouseLeft && ev.Action == termlib.MousePress {
+ *           selectRow(ev.Y)
+ *       }
+ */
+type MouseEvent struct {
+	X      int
+	Y      int
+	Button MouseButton
+	Action MouseAction
+	Mod    Modifier
+}
+
+/** MouseMode selects which mouse actions the terminal reports. Each mode
+ * includes the ones before it.
+ */
+type MouseMode int
+
+const (
+	MouseOff    MouseMode = iota // no mouse reporting
+	MouseClicks                  // button presses, releases and the wheel
+	MouseDrag                    // also motion while a button is held
+	MouseAll                     // also motion with no button held
+)
+
+// mouseModeParams are the DEC private modes that turn on each MouseMode.
+var mouseModeParams = map[MouseMode]string{
+	MouseClicks: "1000",
+	MouseDrag:   "1002",
+	MouseAll:    "1003",
+}
+
+/** EnableMouse turns on mouse reporting in the given mode, replacing any
+ * mode enabled earlier. Reports use the SGR (1006) encoding, which has no
+ * limit on the column or row; terminals that lack it fall back to the
+ * legacy X10 encoding, which ReadEvent also decodes. The request is
+ * written immediately. On a terminal without mouse support (see
+ * Capabilities) nothing is sent and GetMouseMode stays MouseOff.
+ *
+ * Parameters:
+ *   mode (MouseMode) — which actions to report; MouseOff disables them.
+ *
+ * Example:
+ *   term.EnableMouse(termlib.MouseClicks)
+ *   defer term.DisableMouse()
+ */
+func (t *Terminal) EnableMouse(mode MouseMode) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.caps.Mouse || t.output == OutputLines {
+		return
+	}
+	if seq := t.mouseModeSeq(mode); seq != "" {
+		t.write([]byte(seq))
+	}
+	t.trackModes()
+}
+
+// mouseModeSeq records mode as the mouse mode and returns the sequence
+// that switches the terminal to it. t.mu must be held.
+func (t *Terminal) mouseModeSeq(mode MouseMode) string {
+	prev := t.mouseMode
+	t.mouseMode = mode
+	var seq strings.Builder
+	if p, ok := mouseModeParams[prev]; ok && prev != mode {
+		seq.WriteString("\033[?" + p + "l")
+	}
+	if p, ok := mouseModeParams[mode]; ok {
+		seq.WriteString("\033[?" + p + "h\033[?1006h")
+	} else if prev != MouseOff {
+		seq.WriteString("\033[?1006l")
+	}
+	return seq.String()
+}
+
+// DisableMouse turns mouse reporting off.
+func (t *Terminal) DisableMouse() {
+	t.EnableMouse(MouseOff)
+}
+
+// GetMouseMode returns the mouse reporting mode set by EnableMouse.
+func (t *Terminal) GetMouseMode() MouseMode {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.mouseMode
+}
+
+// mouseFromCode builds a MouseEvent from the button code shared by the
+// SGR and X10 encodings. release is true for an SGR release report.
+func mouseFromCode(code, x, y int, release bool) MouseEvent {
+	ev := MouseEvent{X: x, Y: y, Action: MousePress}
+	if code&4 != 0 {
+		ev.Mod |= ModShift
+	}
+	if code&8 != 0 {
+		ev.Mod |= ModAlt
+	}
+	if code&16 != 0 {
+		ev.Mod |= ModCtrl
+	}
+	switch {
+	case code&128 != 0: // buttons 8–11; not distinguished
+		ev.Button = MouseNone
+	case code&64 != 0:
+		ev.Button = MouseWheelUp + MouseButton(code&3)
+	case code&3 == 3:
+		// X10 reports every release as button 3.
+		ev.Button = MouseNone
+		release = release || code&32 == 0
+	default:
+		ev.Button = MouseLeft + MouseButton(code&3)
+	}
+	switch {
+	case code&32 != 0:
+		ev.Action = MouseMotion
+	case release:
+		ev.Action = MouseRelease
+	}
+	return ev
+}
+
+// parseSGRMouse decodes an SGR (mode 1006) mouse report of the form
+// "[<b;x;yM" (press or motion) or "[<b;x;ym" (release).
+func parseSGRMouse(seq string) (MouseEvent, bool) {
+	body := seq[2:]
+	if len(body) < 1 {
+		return MouseEvent{}, false
+	}
+	final := body[len(body)-1]
+	if final != 'M' && final != 'm' {
+		return MouseEvent{}, false
+	}
+	parts := strings.Split(body[:len(body)-1], ";")
+	if len(parts) != 3 {
+		return MouseEvent{}, false
+	}
+	var n [3]int
+	for i, p := range parts {
+		v, err := strconv.Atoi(p)
+		if err != nil {
+			return MouseEvent{}, false
+		}
+		n[i] = v
+	}
+	return mouseFromCode(n[0], n[1], n[2], final == 'm'), true
+}
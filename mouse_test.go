@@ -0,0 +1,91 @@
+// mouse_test.go — tests for mouse modes and mouse report decoding.
+// Copyright (C) 2025 R. S. Doiel
+package termlib
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestParseSGRMouse(t *testing.T) {
+	tests := []struct {
+		seq  string
+		want MouseEvent
+	}{
+		{"[<0;1;1M", MouseEvent{X: 1, Y: 1, Button: MouseLeft, Action: MousePress}},
+		{"[<2;300;120m", MouseEvent{X: 300, Y: 120, Button: MouseRight, Action: MouseRelease}},
+		{"[<32;4;5M", MouseEvent{X: 4, Y: 5, Button: MouseLeft, Action: MouseMotion}},
+		{"[<65;2;3M", MouseEvent{X: 2, Y: 3, Button: MouseWheelDown, Action: MousePress}},
+		{"[<66;2;3M", MouseEvent{X: 2, Y: 3, Button: MouseWheelLeft, Action: MousePress}},
+		{"[<17;9;9M", MouseEvent{X: 9, Y: 9, Button: MouseMiddle, Action: MousePress, Mod: ModCtrl}},
+		{"[<12;9;9M", MouseEvent{X: 9, Y: 9, Button: MouseLeft, Action: MousePress, Mod: ModShift | ModAlt}},
+	}
+	for _, tt := range tests {
+		got, ok := parseSGRMouse(tt.seq)
+		if !ok || got != tt.want {
+			t.Errorf("parseSGRMouse(%q) = %#v, %v; want %#v", tt.seq, got, ok, tt.want)
+		}
+	}
+	for _, bad := range []string{"[<", "[<1;2M", "[<a;b;cM", "[<1;2;3x"} {
+		if _, ok := parseSGRMouse(bad); ok {
+			t.Errorf("parseSGRMouse(%q) accepted", bad)
+		}
+	}
+}
+
+func TestReadEventX10Mouse(t *testing.T) {
+	// Left press at column 10, row 5, then the release (button 3).
+	in := pipeInput(t, "\033[M *%\033[M#*%k")
+	want := []Event{
+		MouseEvent{X: 10, Y: 5, Button: MouseLeft, Action: MousePress},
+		MouseEvent{X: 10, Y: 5, Button: MouseNone, Action: MouseRelease},
+		KeyEvent{Key: Key('k')},
+	}
+	for i, w := range want {
+		got, err := ReadEvent(in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != w {
+			t.Errorf("event %d: got %#v, want %#v", i, got, w)
+		}
+	}
+}
+
+func TestReadKeySkipsMouseReports(t *testing.T) {
+	in := pipeInput(t, "\033[M *%q")
+	k, _ := ReadKey(in)
+	if k != KeyUnknown {
+		t.Errorf("got %v, want KeyUnknown", k)
+	}
+	if k, _ = ReadKey(in); k != Key('q') {
+		t.Errorf("got %v, want q", k)
+	}
+}
+
+func TestEnableMouse(t *testing.T) {
+	var out bytes.Buffer
+	term := New(&out)
+	term.EnableMouse(MouseClicks)
+	if got := out.String(); got != "\033[?1000h\033[?1006h" {
+		t.Errorf("enable: got %q", got)
+	}
+	out.Reset()
+	term.EnableMouse(MouseAll)
+	if got := out.String(); got != "\033[?1000l\033[?1003h\033[?1006h" {
+		t.Errorf("switch: got %q", got)
+	}
+	if term.GetMouseMode() != MouseAll {
+		t.Errorf("mode not recorded")
+	}
+	out.Reset()
+	term.DisableMouse()
+	if got := out.String(); got != "\033[?1003l\033[?1006l" {
+		t.Errorf("disable: got %q", got)
+	}
+	out.Reset()
+	term.DisableMouse()
+	if out.Len() != 0 {
+		t.Errorf("disabling twice wrote %q", out.String())
+	}
+}
@@ -0,0 +1,122 @@
+// output.go — plain text rendering for output that is not a terminal.
+// Copyright (C) 2025 R. S. Doiel
+package termlib
+
+import (
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+/** OutputMode selects how Refresh renders the cell buffer. Output
+ * redirected to a file or a pipe has no use for cursor moves and color
+ * codes, and a dumb terminal cannot show them.
+ */
+type OutputMode int
+
+const (
+	OutputANSI  OutputMode = iota // cursor-addressed output with colors and attributes
+	OutputPlain                   // cursor-addressed output without colors or attributes
+	OutputLines                   // changed rows as lines of plain text, with no escape sequences
+)
+
+/** DetectOutputMode suggests how output written to w should be rendered,
+ * for a program that wants plain lines when it is not writing to a
+ * terminal. A file that is not a terminal, such as stdout redirected to a
+ * file or a pipe, and any writer when TERM is "dumb", get OutputLines.
+ * Other writers get OutputANSI, including those that are not files, such
+ * as an SSH channel. NO_COLOR is not an output mode: it turns off colors
+ * through Capabilities and keeps the other attributes.
+ *
+ * New does not call it: a Terminal renders in OutputANSI until the
+ * program chooses another mode with SetOutputMode.
+ *
+ * Parameters:
+ *   w (io.Writer) — the writer the Terminal draws to.
+ *
+ * Returns:
+ *   OutputMode — the suggested mode for w.
+ *
+ * Example:
+ *   term := termlib.New(os.Stdout)
+ *   term.SetOutputMode(termlib.DetectOutputMode(os.Stdout))
+ */
+func DetectOutputMode(w io.Writer) OutputMode {
+	if os.Getenv("TERM") == "dumb" {
+		return OutputLines
+	}
+	if f, ok := w.(interface{ Fd() uintptr }); ok && !term.IsTerminal(int(f.Fd())) {
+		return OutputLines
+	}
+	return OutputANSI
+}
+
+// GetOutputMode returns how Refresh renders the cell buffer.
+func (t *Terminal) GetOutputMode() OutputMode {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.output
+}
+
+/** SetOutputMode chooses how Refresh renders the cell buffer, in place of
+ * the default OutputANSI, for example to honour a --plain command line
+ * option or to write plain lines when output is redirected (see
+ * DetectOutputMode). The next Refresh repaints the whole screen in the new
+ * mode.
+ *
+ * In OutputLines mode nothing but text is written: the alternate screen,
+ * mouse reporting, the kitty keyboard protocol, terminal queries and the
+ * other modes are left off, and the cursor is neither moved nor hidden.
+ *
+ * Parameters:
+ *   m (OutputMode) — the rendering to use.
+ *
+ * Example:
+ *   if *plain {
+ *       term.SetOutputMode(termlib.OutputPlain)
+ *   }
+ */
+func (t *Terminal) SetOutputMode(m OutputMode) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if m == t.output {
+		return
+	}
+	t.output = m
+	t.render.noStyle = m != OutputANSI
+	if t.drawn {
+		t.invalidate()
+	}
+}
+
+// refreshLines is Refresh for OutputLines: each row of the frame that
+// changed since the last Refresh and is not blank is written as a line of
+// text without its trailing spaces. t.mu must be held.
+func (t *Terminal) refreshLines() {
+	if t.clearPending {
+		t.front.fill(blankCell)
+		t.clearPending = false
+	}
+	for row := 1; row <= t.back.height; row++ {
+		changed := false
+		for col := 1; col <= t.back.width; col++ {
+			if b, f := t.back.at(row, col), t.front.at(row, col); *b != *f {
+				*f = *b
+				changed = true
+			}
+		}
+		if !changed {
+			continue
+		}
+		var line strings.Builder
+		for col := 1; col <= t.back.lastNonBlank(row); col++ {
+			line.WriteString(t.back.at(row, col).ch) // right halves of wide characters are ""
+		}
+		if line.Len() > 0 {
+			t.buf.WriteString(line.String() + "\n")
+		}
+	}
+	t.drawn = true
+}
@@ -0,0 +1,92 @@
+// output_test.go — tests for plain text rendering.
+// Copyright (C) 2025 R. S. Doiel
+package termlib
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestDetectOutputMode(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "out"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if m := DetectOutputMode(f); m != OutputLines {
+		t.Errorf("file: got %v", m)
+	}
+	if m := DetectOutputMode(&bytes.Buffer{}); m != OutputANSI {
+		t.Errorf("buffer: got %v", m)
+	}
+	// New keeps full rendering until the program chooses otherwise.
+	if m := New(f).GetOutputMode(); m != OutputANSI {
+		t.Errorf("New(file): got %v", m)
+	}
+	t.Setenv("TERM", "dumb")
+	if m := DetectOutputMode(&bytes.Buffer{}); m != OutputLines {
+		t.Errorf("TERM=dumb: got %v", m)
+	}
+}
+
+func TestRefreshLines(t *testing.T) {
+	resetRegistry(t)
+	var out bytes.Buffer
+	term := New(&out)
+	term.SetOutputMode(OutputLines)
+	term.SetSize(10, 3)
+	term.EnterAltScreen()
+	term.EnableMouse(MouseClicks)
+	term.EnableBracketedPaste()
+	term.HideCursor()
+	term.SetCursorShape(CursorSteadyBar)
+	term.PrintStyled(NewStyle().Fg(Red).Bold(), "hi")
+	term.Move(3, 1)
+	term.Print("x 世")
+	term.Refresh()
+	if got := out.String(); got != "hi\nx 世\n" {
+		t.Errorf("first frame: got %q", got)
+	}
+	if registered() != 0 || term.InAltScreen() || term.GetMouseMode() != MouseOff {
+		t.Error("modes set in OutputLines mode")
+	}
+
+	out.Reset()
+	term.Move(3, 1)
+	term.Print("y")
+	term.Refresh()
+	if got := out.String(); got != "y 世\n" {
+		t.Errorf("second frame: got %q", got)
+	}
+
+	// Switching back repaints the whole screen with escape sequences.
+	out.Reset()
+	term.SetOutputMode(OutputANSI)
+	term.Refresh()
+	if got := out.String(); !strings.Contains(got, "\033[2J\033[?25l") || !strings.Contains(got, "\033[31;1mhi") {
+		t.Errorf("not repainted: %q", got)
+	}
+	RestoreTerminal()
+}
+
+func TestRefreshPlain(t *testing.T) {
+	var out bytes.Buffer
+	term := New(&out)
+	term.SetOutputMode(OutputPlain)
+	term.SetSize(10, 2)
+	term.PrintStyled(NewStyle().Fg(Red).Bold().Underline(UnderlineCurly), "hi")
+	term.Move(2, 3)
+	term.Print("there")
+	term.Refresh()
+	got := out.String()
+	if regexp.MustCompile(`\x1b\[[0-9;:]*m`).MatchString(got) {
+		t.Errorf("SGR sequence sent: %q", got)
+	}
+	if !strings.Contains(got, "hi") || !strings.Contains(got, "\033[2;3Hthere") {
+		t.Errorf("got %q", got)
+	}
+}
@@ -2,24 +2,28 @@
 // Copyright (C) 2025 R. S. Doiel
 package termlib
 
-import (
-	"os"
-
-	"golang.org/x/term"
-)
+import "io"
 
 /** EnterRawMode puts the terminal associated with in into raw mode and
  * returns a restore function that the caller must defer. If the terminal
  * cannot be placed into raw mode (e.g. in is a pipe), an error is returned
  * and the restore function is a no-op.
  *
+ * in may be any reader with a terminal behind it: a file open on a
+ * terminal, or a reader implementing TTY.
+ *
+ * Raw mode is also left by RestoreTerminal, so a program killed by a
+ * signal, or panicking under RestoreOnPanic, does not leave the user's
+ * terminal in raw mode.
+ *
  * Parameters:
- *   in (*os.File) — the file to place in raw mode, typically os.Stdin.
+ *   in (io.Reader) — the input to place in raw mode, typically os.Stdin.
  *
  * Returns:
  *   restore (func()) — call (or defer) this to return the terminal to its
  *                      original state.
- *   err (error)      — non-nil if raw mode could not be entered.
+ *   err (error)      — non-nil if raw mode could not be entered;
+ *                      ErrNotTerminal when in has no terminal behind it.
  *
  * Example:
  *   restore, err := termlib.EnterRawMode(os.Stdin)
@@ -28,11 +32,14 @@ import (
  *   }
  *   defer restore()
  */
-func EnterRawMode(in *os.File) (restore func(), err error) {
-	fd := int(in.Fd())
-	oldState, err := term.MakeRaw(fd)
+func EnterRawMode(in io.Reader) (restore func(), err error) {
+	tty := ttyFor(in)
+	if tty == nil {
+		return func() {}, ErrNotTerminal
+	}
+	restore, err = makeRaw(tty)
 	if err != nil {
 		return func() {}, err
 	}
-	return func() { term.Restore(fd, oldState) }, nil
+	return restore, nil
 }
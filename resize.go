@@ -0,0 +1,84 @@
+// resize.go — terminal window size change notifications.
+// Copyright (C) 2025 R. S. Doiel
+package termlib
+
+import (
+	"sync"
+
+	"golang.org/x/term"
+)
+
+// ResizeEvent reports the new size of the terminal window in columns
+// and rows.
+type ResizeEvent struct {
+	Width  int
+	Height int
+}
+
+/** WatchResize starts watching for terminal window size changes (SIGWINCH
+ * on Unix; polled on Windows). Each change updates the Terminal's width,
+ * height and cell buffers and is then reported on the returned channel,
+ * so the next Refresh repaints the screen at the new size. Only the most
+ * recent size is kept if the receiver falls behind. Call stop to end the
+ * watch; the channel is closed afterwards.
+ *
+ * Returns:
+ *   <-chan ResizeEvent — receives the new size after each change.
+ *   stop (func())      — stops watching; safe to call more than once.
+ *
+ * Example:
+ *   resized, stop := term.WatchResize()
+ *   defer stop()
+ *   for {
+ *       select {
+ *       case <-resized:
+ *           redraw(term)
+ *       case k := <-keys:
+ *           ...
+ *       }
+ *   }
+ */
+func (t *Terminal) WatchResize() (<-chan ResizeEvent, func()) {
+	ch := make(chan ResizeEvent, 1)
+	sig, stopSig := notifyResize()
+	done := make(chan struct{})
+	go func() {
+		defer close(ch)
+		for {
+			select {
+			case <-done:
+				return
+			case <-sig:
+				if ev, changed := t.resizeFromTerminal(); changed {
+					select {
+					case <-ch: // discard a size nobody has read yet
+					default:
+					}
+					ch <- ev
+				}
+			}
+		}
+	}()
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			stopSig()
+			close(done)
+		})
+	}
+}
+
+// resizeFromTerminal re-reads the window size and applies it, reporting
+// the new size and whether it changed.
+func (t *Terminal) resizeFromTerminal() (ResizeEvent, bool) {
+	if t.sizeFd < 0 {
+		return ResizeEvent{}, false
+	}
+	width, height, err := term.GetSize(t.sizeFd)
+	if err != nil || width < 1 || height < 1 {
+		return ResizeEvent{}, false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return ResizeEvent{Width: width, Height: height}, t.setSize(width, height)
+}
@@ -0,0 +1,27 @@
+// resize_test.go — tests for resize notifications.
+// Copyright (C) 2025 R. S. Doiel
+package termlib
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestWatchResizeStop(t *testing.T) {
+	var buf bytes.Buffer
+	term := New(&buf)
+
+	resized, stop := term.WatchResize()
+	stop()
+	stop() // safe to call twice
+
+	select {
+	case _, ok := <-resized:
+		if ok {
+			t.Error("received a resize from a writer with no window size")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("channel not closed after stop")
+	}
+}
@@ -0,0 +1,37 @@
+//go:build !windows
+
+// resize_unix.go — SIGWINCH delivery for resize notifications.
+// Copyright (C) 2025 R. S. Doiel
+package termlib
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// notifyResize returns a channel that receives a value whenever the
+// process gets SIGWINCH, and a function that stops the notifications.
+func notifyResize() (<-chan struct{}, func()) {
+	sig := make(chan os.Signal, 1)
+	out := make(chan struct{}, 1)
+	done := make(chan struct{})
+	signal.Notify(sig, syscall.SIGWINCH)
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-sig:
+				select {
+				case out <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}()
+	return out, func() {
+		signal.Stop(sig)
+		close(done)
+	}
+}
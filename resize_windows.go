@@ -0,0 +1,37 @@
+//go:build windows
+
+// resize_windows.go — polled resize notifications for Windows consoles.
+// Copyright (C) 2025 R. S. Doiel
+package termlib
+
+import "time"
+
+// resizePollInterval is how often the console size is checked. Windows
+// has no SIGWINCH, so callers re-read the size on every tick and only
+// act when it has changed.
+const resizePollInterval = 250 * time.Millisecond
+
+// notifyResize returns a channel that receives a value on every poll
+// tick, and a function that stops the ticker.
+func notifyResize() (<-chan struct{}, func()) {
+	out := make(chan struct{}, 1)
+	done := make(chan struct{})
+	ticker := time.NewTicker(resizePollInterval)
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				select {
+				case out <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}()
+	return out, func() {
+		ticker.Stop()
+		close(done)
+	}
+}
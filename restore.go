@@ -0,0 +1,215 @@
+// restore.go — returning the terminal to a usable state however the program ends.
+// Copyright (C) 2025 R. S. Doiel
+package termlib
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// restorer is one registered way of undoing a terminal mode change. fn
+// undoes the change for good; suspend and resume undo and redo it around
+// a stop of the process (see Suspend).
+type restorer struct {
+	fn      func()
+	suspend func()
+	resume  func()
+}
+
+// restoreRegistry holds the restorers for every terminal mode changed
+// through termlib that has not been undone yet. While it is non-empty,
+// the signals chosen with SetRestoreSignals are caught so that the modes
+// can be undone first.
+var restoreRegistry struct {
+	mu    sync.Mutex
+	list  []*restorer
+	sigs  chan os.Signal
+	close chan struct{}
+}
+
+// TerminationSignals are the signals that usually end a terminal
+// program: SIGINT, SIGTERM, SIGHUP and SIGQUIT.
+var TerminationSignals = []os.Signal{os.Interrupt, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGQUIT}
+
+// restoreSignals are the signals on which the terminal is restored before
+// the program ends; see SetRestoreSignals. restoreRegistry.mu guards it.
+var restoreSignals []os.Signal
+
+/** SetRestoreSignals asks termlib to catch sigs while any terminal mode it
+ * has set is in effect, call RestoreTerminal, and then let the signal take
+ * its default effect, ending the program. No signals are caught until it
+ * is called, so that a program's own signal handling is left alone.
+ * Leave out any signal the program handles itself with signal.Notify, and
+ * call RestoreTerminal from that handler instead: termlib resets the
+ * signals it catches before raising them again. Signals that are ignored,
+ * such as SIGHUP under nohup, are never caught. Calling it with no
+ * arguments stops catching signals.
+ *
+ * Parameters:
+ *   sigs (...os.Signal) — the signals to restore the terminal on.
+ *
+ * Example:
+ *   termlib.SetRestoreSignals(termlib.TerminationSignals...)
+ */
+func SetRestoreSignals(sigs ...os.Signal) {
+	reg := &restoreRegistry
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	restoreSignals = append([]os.Signal(nil), sigs...)
+	stopSignals()
+	if len(reg.list) > 0 {
+		startSignals()
+	}
+}
+
+// registerRestore records fn as undoing a terminal mode change, to be run
+// by RestoreTerminal, and suspend and resume as undoing and redoing it for
+// Suspend.
+func registerRestore(fn, suspend, resume func()) *restorer {
+	reg := &restoreRegistry
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	r := &restorer{fn: fn, suspend: suspend, resume: resume}
+	reg.list = append(reg.list, r)
+	if len(reg.list) == 1 {
+		startSignals()
+	}
+	return r
+}
+
+// unregister removes r once the change it undoes has been undone.
+func (r *restorer) unregister() {
+	reg := &restoreRegistry
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	for i, e := range reg.list {
+		if e == r {
+			reg.list = append(reg.list[:i], reg.list[i+1:]...)
+			if len(reg.list) == 0 {
+				stopSignals()
+			}
+			return
+		}
+	}
+}
+
+// startSignals starts catching those of restoreSignals that are not
+// ignored. restoreRegistry.mu must be held.
+func startSignals() {
+	reg := &restoreRegistry
+	var sigs []os.Signal
+	for _, sig := range restoreSignals {
+		if !signal.Ignored(sig) {
+			sigs = append(sigs, sig)
+		}
+	}
+	if len(sigs) == 0 {
+		return
+	}
+	reg.sigs = make(chan os.Signal, 1)
+	reg.close = make(chan struct{})
+	signal.Notify(reg.sigs, sigs...)
+	go catchSignals(reg.sigs, reg.close)
+}
+
+// stopSignals returns signal handling to the default once there is
+// nothing to restore. restoreRegistry.mu must be held.
+func stopSignals() {
+	reg := &restoreRegistry
+	if reg.sigs == nil {
+		return
+	}
+	signal.Stop(reg.sigs)
+	close(reg.close)
+	reg.sigs, reg.close = nil, nil
+}
+
+// catchSignals restores the terminal when a termination signal arrives,
+// until done is closed, and then lets the signal take its default effect.
+func catchSignals(sigs <-chan os.Signal, done <-chan struct{}) {
+	select {
+	case sig := <-sigs:
+		RestoreTerminal()
+		signal.Reset(sig)
+		raise(sig)
+	case <-done:
+	}
+}
+
+// signalExitCode is the exit status of a process ended by sig.
+func signalExitCode(sig os.Signal) int {
+	if s, ok := sig.(syscall.Signal); ok {
+		return 128 + int(s)
+	}
+	return 1
+}
+
+/** RestoreTerminal undoes every terminal mode that termlib has changed and
+ * not yet changed back, most recent first: it shows a hidden cursor and
+ * gives it back its own shape and color, turns off mouse reporting, leaves
+ * the alternate screen and leaves raw mode.
+ * It is called by RestoreOnPanic and, for the signals chosen with
+ * SetRestoreSignals, when one is received while any such mode is in
+ * effect, before the signal ends the program as usual. Calling it when
+ * nothing needs restoring does nothing.
+ *
+ * Example:
+ *   if err := run(); err != nil {
+ *       termlib.RestoreTerminal()
+ *       log.Fatal(err) // os.Exit skips deferred restores
+ *   }
+ */
+func RestoreTerminal() {
+	reg := &restoreRegistry
+	reg.mu.Lock()
+	list := reg.list
+	reg.list = nil
+	stopSignals()
+	reg.mu.Unlock()
+	for i := len(list) - 1; i >= 0; i-- {
+		list[i].fn()
+	}
+}
+
+/** RestoreOnPanic restores the terminal if the surrounding function is
+ * panicking, then lets the panic continue so that its message and stack
+ * trace print on a usable terminal. It must be deferred directly.
+ *
+ * Example:
+ *   func main() {
+ *       defer termlib.RestoreOnPanic()
+ *       ...
+ *   }
+ */
+func RestoreOnPanic() {
+	if r := recover(); r != nil {
+		RestoreTerminal()
+		panic(r)
+	}
+}
+
+/** Guard runs fn with RestoreOnPanic deferred. A panic in any goroutine
+ * ends the program, so start goroutines of a terminal application with
+ * Guard to have the terminal restored whichever one panics.
+ *
+ * Parameters:
+ *   fn (func()) — the function to run.
+ *
+ * Example:
+ *   go termlib.Guard(func() { watchFiles(updates) })
+ */
+func Guard(fn func()) {
+	defer RestoreOnPanic()
+	fn()
+}
+
+// registeredRestorers returns a copy of the registered restorers, oldest
+// first.
+func registeredRestorers() []*restorer {
+	reg := &restoreRegistry
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	return append([]*restorer(nil), reg.list...)
+}
@@ -0,0 +1,138 @@
+// restore_test.go — tests for the terminal restoration registry.
+// Copyright (C) 2025 R. S. Doiel
+package termlib
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+// resetRegistry restores and forgets anything earlier tests registered.
+func resetRegistry(t *testing.T) {
+	t.Helper()
+	RestoreTerminal()
+	t.Cleanup(RestoreTerminal)
+}
+
+// nop is a restorer step that does nothing.
+func nop() {}
+
+// registered returns how many restorers are registered.
+func registered() int {
+	restoreRegistry.mu.Lock()
+	defer restoreRegistry.mu.Unlock()
+	return len(restoreRegistry.list)
+}
+
+func TestRestoreTerminalOrder(t *testing.T) {
+	resetRegistry(t)
+	SetRestoreSignals(TerminationSignals...)
+	defer SetRestoreSignals()
+	var order []int
+	registerRestore(func() { order = append(order, 1) }, nop, nop)
+	r := registerRestore(func() { order = append(order, 2) }, nop, nop)
+	registerRestore(func() { order = append(order, 3) }, nop, nop)
+	r.unregister()
+	if restoreRegistry.sigs == nil {
+		t.Error("signals not caught while restorers are registered")
+	}
+	RestoreTerminal()
+	if len(order) != 2 || order[0] != 3 || order[1] != 1 {
+		t.Errorf("got order %v, want [3 1]", order)
+	}
+	if registered() != 0 || restoreRegistry.sigs != nil {
+		t.Error("registry not cleared")
+	}
+	RestoreTerminal() // nothing left: no effect
+	if len(order) != 2 {
+		t.Errorf("restorers ran twice: %v", order)
+	}
+}
+
+func TestSignalsNotCaughtByDefault(t *testing.T) {
+	resetRegistry(t)
+	New(&bytes.Buffer{}).EnterAltScreen()
+	if restoreRegistry.sigs != nil {
+		t.Error("signals caught without SetRestoreSignals")
+	}
+	SetRestoreSignals(os.Interrupt)
+	defer SetRestoreSignals()
+	if restoreRegistry.sigs == nil {
+		t.Error("SetRestoreSignals did not start catching signals")
+	}
+}
+
+func TestTerminalModesRegistered(t *testing.T) {
+	resetRegistry(t)
+	var out bytes.Buffer
+	term := New(&out)
+	term.SetSize(10, 2)
+	term.HideCursor()
+	term.Refresh()
+	term.EnterAltScreen()
+	term.EnableMouse(MouseDrag)
+	if registered() != 1 {
+		t.Fatalf("want the terminal registered once, got %d", registered())
+	}
+	out.Reset()
+	RestoreTerminal()
+	if got := out.String(); got != "\033[?25h\033[?1002l\033[?1006l\033[?1049l" {
+		t.Errorf("restore sequence: %q", got)
+	}
+	if registered() != 0 {
+		t.Error("registry not cleared")
+	}
+}
+
+func TestTerminalUnregistersWhenModesOff(t *testing.T) {
+	resetRegistry(t)
+	term := New(&bytes.Buffer{})
+	term.EnableMouse(MouseClicks)
+	term.EnterAltScreen()
+	term.DisableMouse()
+	if registered() != 1 {
+		t.Fatalf("want 1 registered, got %d", registered())
+	}
+	term.ExitAltScreen()
+	if registered() != 0 {
+		t.Errorf("still registered after all modes turned off")
+	}
+}
+
+func TestRawModeRegistered(t *testing.T) {
+	resetRegistry(t)
+	raw := false
+	restore, err := EnterRawMode(rawReader{strings.NewReader(""), &raw})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if registered() != 1 {
+		t.Fatalf("raw mode not registered")
+	}
+	RestoreTerminal()
+	if raw {
+		t.Error("raw mode not left")
+	}
+	restore() // already restored: harmless
+	if registered() != 0 {
+		t.Error("registry not cleared")
+	}
+}
+
+func TestGuardRestoresOnPanic(t *testing.T) {
+	resetRegistry(t)
+	var out bytes.Buffer
+	term := New(&out)
+	term.EnterAltScreen()
+	defer func() {
+		if r := recover(); r != "boom" {
+			t.Errorf("panic value %v", r)
+		}
+		if term.InAltScreen() || registered() != 0 {
+			t.Error("terminal not restored")
+		}
+	}()
+	Guard(func() { panic("boom") })
+}
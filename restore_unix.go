@@ -0,0 +1,20 @@
+//go:build !windows
+
+// restore_unix.go — re-raising a caught termination signal.
+// Copyright (C) 2025 R. S. Doiel
+package termlib
+
+import (
+	"os"
+	"syscall"
+)
+
+// raise sends sig to the process again once it is no longer caught, so
+// that it has its default effect: the exit status shows the signal, and
+// SIGQUIT still dumps the goroutines.
+func raise(sig os.Signal) {
+	if s, ok := sig.(syscall.Signal); ok && syscall.Kill(syscall.Getpid(), s) == nil {
+		return
+	}
+	os.Exit(signalExitCode(sig))
+}
@@ -0,0 +1,92 @@
+//go:build !windows
+
+// restore_unix_test.go — tests for restoring the terminal on signals.
+// Copyright (C) 2025 R. S. Doiel
+package termlib
+
+import (
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// signalChild runs this test binary again as a child that acts out the
+// scenario named by TERMLIB_SIGNAL_TEST, or acts it out when it is the
+// child. It returns the child's output and error.
+func signalChild(t *testing.T, test, scenario string) (string, error) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], "-test.run=^"+test+"$")
+	cmd.Env = append(os.Environ(), "TERMLIB_SIGNAL_TEST="+scenario)
+	out, err := cmd.CombinedOutput()
+	return string(out), err
+}
+
+// childTerminal returns a Terminal on stdout in the alternate screen. The
+// writer hides stdout's descriptor, so that its modes are written although
+// stdout is a pipe.
+func childTerminal() *Terminal {
+	term := New(struct{ io.Writer }{os.Stdout})
+	term.EnterAltScreen()
+	return term
+}
+
+func TestSignalRaisedAfterRestore(t *testing.T) {
+	if os.Getenv("TERMLIB_SIGNAL_TEST") == "default" {
+		SetRestoreSignals(TerminationSignals...)
+		childTerminal()
+		syscall.Kill(syscall.Getpid(), syscall.SIGTERM)
+		time.Sleep(5 * time.Second)
+		os.Exit(0) // not reached: SIGTERM ends the process
+	}
+	out, err := signalChild(t, "TestSignalRaisedAfterRestore", "default")
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		t.Fatalf("child: %v\n%s", err, out)
+	}
+	ws := exitErr.Sys().(syscall.WaitStatus)
+	if !ws.Signaled() || ws.Signal() != syscall.SIGTERM {
+		t.Errorf("child ended with %v, want killed by SIGTERM", err)
+	}
+	if !strings.HasSuffix(out, "\033[?1049h\033[?1049l") {
+		t.Errorf("terminal not restored: %q", out)
+	}
+}
+
+func TestSignalHandledByProgram(t *testing.T) {
+	if os.Getenv("TERMLIB_SIGNAL_TEST") == "handled" {
+		interrupts := make(chan os.Signal, 1)
+		signal.Notify(interrupts, os.Interrupt)
+		childTerminal()
+		syscall.Kill(syscall.Getpid(), syscall.SIGINT)
+		select {
+		case <-interrupts:
+			RestoreTerminal()
+			os.Stdout.WriteString("cleaned up")
+			os.Exit(0)
+		case <-time.After(5 * time.Second):
+			os.Exit(3)
+		}
+	}
+	out, err := signalChild(t, "TestSignalHandledByProgram", "handled")
+	if err != nil || !strings.HasSuffix(out, "\033[?1049h\033[?1049lcleaned up") {
+		t.Errorf("child: %v, %q", err, out)
+	}
+}
+
+func TestIgnoredSignalNotCaught(t *testing.T) {
+	resetRegistry(t)
+	signal.Ignore(syscall.SIGHUP)
+	defer signal.Reset(syscall.SIGHUP)
+	SetRestoreSignals(syscall.SIGHUP)
+	defer SetRestoreSignals()
+	New(&strings.Builder{}).EnterAltScreen()
+	if !signal.Ignored(syscall.SIGHUP) {
+		t.Error("SIGHUP no longer ignored")
+	}
+}
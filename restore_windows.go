@@ -0,0 +1,13 @@
+//go:build windows
+
+// restore_windows.go — ending the process after a caught signal.
+// Copyright (C) 2025 R. S. Doiel
+package termlib
+
+import "os"
+
+// raise ends the process as sig would have. Windows cannot send a
+// process a signal, so it exits with the status a shell reports for one.
+func raise(sig os.Signal) {
+	os.Exit(signalExitCode(sig))
+}
@@ -0,0 +1,240 @@
+// screen.go — double-buffered cell grid and frame diffing for Terminal.
+// Copyright (C) 2025 R. S. Doiel
+package termlib
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// cell is one character position on the screen. ch holds a whole
+// grapheme cluster. A wide cluster occupies its own cell with width 2 and
+// the cell to its right, which is a continuation cell with width 0 and an
+// empty ch.
+type cell struct {
+	ch    string
+	width int
+	style Style
+}
+
+// blankCell is an empty, unstyled cell.
+var blankCell = cell{ch: " ", width: 1, style: defaultStyle}
+
+// grid is a width × height matrix of cells addressed with 1-based
+// row and column numbers, matching ANSI cursor addressing.
+type grid struct {
+	width  int
+	height int
+	cells  []cell
+}
+
+// newGrid returns a grid of the given size filled with blank cells.
+func newGrid(width, height int) grid {
+	g := grid{}
+	g.resize(width, height)
+	return g
+}
+
+// resize changes the grid dimensions, keeping the overlapping region
+// and filling any new area with blank cells.
+func (g *grid) resize(width, height int) {
+	if width < 0 {
+		width = 0
+	}
+	if height < 0 {
+		height = 0
+	}
+	cells := make([]cell, width*height)
+	for i := range cells {
+		cells[i] = blankCell
+	}
+	for r := 0; r < height && r < g.height; r++ {
+		for c := 0; c < width && c < g.width; c++ {
+			cells[r*width+c] = g.cells[r*g.width+c]
+		}
+	}
+	g.width, g.height, g.cells = width, height, cells
+}
+
+// at returns the cell at row, col (1-based) or nil when out of range.
+func (g *grid) at(row, col int) *cell {
+	if row < 1 || row > g.height || col < 1 || col > g.width {
+		return nil
+	}
+	return &g.cells[(row-1)*g.width+col-1]
+}
+
+// fill sets every cell in the grid to c.
+func (g *grid) fill(c cell) {
+	for i := range g.cells {
+		g.cells[i] = c
+	}
+}
+
+// fillRow sets columns from through to (inclusive, 1-based) of row to c.
+func (g *grid) fillRow(row, from, to int, c cell) {
+	for col := from; col <= to; col++ {
+		if p := g.at(row, col); p != nil {
+			*p = c
+		}
+	}
+}
+
+// put stores the grapheme cluster ch of display width w at row, col,
+// keeping wide characters consistent: any wide character that the write
+// partially overlaps is replaced with blanks, and a wide character that
+// would not fit before the right margin is written as a blank instead.
+func (g *grid) put(row, col int, ch string, w int, st Style) {
+	p := g.at(row, col)
+	if p == nil {
+		return
+	}
+	if p.width == 0 {
+		// Overwriting the right half of a wide character.
+		if left := g.at(row, col-1); left != nil {
+			*left = cell{ch: " ", width: 1, style: left.style}
+		}
+	}
+	if p.width == 2 {
+		if right := g.at(row, col+1); right != nil {
+			*right = cell{ch: " ", width: 1, style: right.style}
+		}
+	}
+	if w == 2 {
+		right := g.at(row, col+1)
+		if right == nil {
+			*p = cell{ch: " ", width: 1, style: st}
+			return
+		}
+		if right.width == 2 {
+			if next := g.at(row, col+2); next != nil {
+				*next = cell{ch: " ", width: 1, style: next.style}
+			}
+		}
+		*right = cell{style: st}
+	}
+	*p = cell{ch: ch, width: w, style: st}
+}
+
+// lastNonBlank returns the column of the rightmost cell in row that is
+// not blankCell, or 0 when the whole row is blank.
+func (g *grid) lastNonBlank(row int) int {
+	for col := g.width; col >= 1; col-- {
+		if *g.at(row, col) != blankCell {
+			return col
+		}
+	}
+	return 0
+}
+
+// renderer tracks what the physical terminal is showing so that a frame
+// can be written as the minimal set of cursor moves, SGR changes and
+// characters that turn the front buffer into the back buffer.
+type renderer struct {
+	out        *bytes.Buffer
+	caps       Capabilities
+	row        int // physical cursor row; 0 when unknown
+	col        int // physical cursor column; 0 when unknown
+	style      Style
+	styleKnown bool
+	noStyle    bool // write no SGR sequences at all (OutputPlain)
+}
+
+// moveTo positions the physical cursor at row, col. When the cursor is
+// already on the right row a short forward move is used, and gaps of a
+// few cells whose contents match the current pen are simply reprinted.
+func (r *renderer) moveTo(back *grid, row, col int) {
+	if r.row == row && r.col == col {
+		return
+	}
+	if r.row == row && col > r.col {
+		gap := col - r.col
+		if gap <= 4 && r.styleKnown {
+			reprint := true
+			for c := r.col; c < col; c++ {
+				if p := back.at(row, c); p.width != 1 || p.style.adapt(r.caps) != r.style {
+					reprint = false
+					break
+				}
+			}
+			if reprint {
+				for c := r.col; c < col; c++ {
+					r.out.WriteString(back.at(row, c).ch)
+				}
+				r.col = col
+				return
+			}
+		}
+		fmt.Fprintf(r.out, "\033[%dC", gap)
+		r.col = col
+		return
+	}
+	if row == 1 && col == 1 {
+		r.out.WriteString("\033[H")
+	} else {
+		fmt.Fprintf(r.out, "\033[%d;%dH", row, col)
+	}
+	r.row, r.col = row, col
+}
+
+// setStyle switches the terminal pen to s, adapted to the terminal's
+// capabilities, using whichever of an incremental change or a reset
+// followed by the full style is shorter.
+func (r *renderer) setStyle(s Style) {
+	if r.noStyle {
+		return
+	}
+	s = s.adapt(r.caps)
+	if r.styleKnown && r.style == s {
+		return
+	}
+	prev, known := r.style, r.styleKnown
+	r.style, r.styleKnown = s, true
+	if s == defaultStyle {
+		r.out.WriteString(Reset)
+		return
+	}
+	params := strings.Join(s.sgrParams(defaultStyle, true), ";")
+	if delta := strings.Join(s.sgrParams(prev, false), ";"); known && len(delta) < len(params) {
+		params = delta
+	}
+	r.out.WriteString("\033[" + params + "m")
+}
+
+// diff writes the changes needed to turn front into back and updates
+// front to match. Trailing runs of blank cells are cleared with a single
+// erase-to-end-of-line instead of being written out space by space.
+func (r *renderer) diff(front, back *grid) {
+	for row := 1; row <= back.height; row++ {
+		lastUsed := back.lastNonBlank(row)
+		for col := 1; col <= back.width; col++ {
+			b, f := back.at(row, col), front.at(row, col)
+			if *b == *f {
+				continue
+			}
+			if b.width == 0 {
+				// Right half of a wide character, drawn with its left half.
+				*f = *b
+				continue
+			}
+			if col > lastUsed {
+				r.moveTo(back, row, col)
+				r.setStyle(defaultStyle)
+				r.out.WriteString("\033[K")
+				front.fillRow(row, col, front.width, blankCell)
+				break
+			}
+			r.moveTo(back, row, col)
+			r.setStyle(b.style)
+			r.out.WriteString(b.ch)
+			*f = *b
+			r.col += b.width
+			if r.col > back.width {
+				// The cursor is in the pending-wrap state; its
+				// position is terminal dependent until the next move.
+				r.row, r.col = 0, 0
+			}
+		}
+	}
+}
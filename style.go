@@ -0,0 +1,253 @@
+// style.go — text attributes and SGR generation.
+// Copyright (C) 2025 R. S. Doiel
+package termlib
+
+import (
+	"strconv"
+	"strings"
+)
+
+/** Attr is a set of text attributes, combined with bitwise OR. Underlining
+ * is not an Attr because it has several styles; see UnderlineStyle.
+ *
+ * Example:
+ *   term.SetAttr(termlib.AttrBold | termlib.AttrReverse)
+ */
+type Attr uint16
+
+const (
+	AttrBold          Attr = 1 << iota // increased intensity (SGR 1)
+	AttrDim                            // decreased intensity (SGR 2)
+	AttrItalic                         // italic (SGR 3)
+	AttrBlink                          // slow blink (SGR 5)
+	AttrReverse                        // swap foreground and background (SGR 7)
+	AttrHidden                         // invisible text (SGR 8)
+	AttrStrikethrough                  // crossed-out text (SGR 9)
+	AttrOverline                       // line above the text (SGR 53)
+)
+
+/** UnderlineStyle selects how text is underlined. Styles other than
+ * UnderlineSingle use the "4:n" extended form understood by kitty, VTE,
+ * WezTerm, iTerm2 and recent xterm; older terminals show a plain underline
+ * or none at all.
+ */
+type UnderlineStyle uint8
+
+const (
+	UnderlineNone   UnderlineStyle = iota // no underline (SGR 24)
+	UnderlineSingle                       // SGR 4
+	UnderlineDouble                       // SGR 4:2
+	UnderlineCurly                        // SGR 4:3
+	UnderlineDotted                       // SGR 4:4
+	UnderlineDashed                       // SGR 4:5
+)
+
+/** Style is an immutable set of colors and text attributes. Build one with
+ * NewStyle and the chained modifier methods, each of which returns a new
+ * Style and leaves the receiver unchanged, so styles can be shared and
+ * extended freely. The zero value is the terminal's default style.
+ *
+ * Example:
+ *   warn := termlib.NewStyle().Fg(termlib.Yellow).Bold()
+ *   alert := warn.Reverse()
+ *   fmt.Println(warn.Render("careful"))
+ *   term.PrintStyled(alert, "stop")
+ */
+type Style struct {
+	fg        Color
+	bg        Color
+	attrs     Attr
+	underline UnderlineStyle
+	ulColor   Color
+}
+
+// defaultStyle is the terminal's unstyled state (what Reset produces).
+var defaultStyle = Style{}
+
+// NewStyle returns the default style, ready for chaining.
+func NewStyle() Style {
+	return Style{}
+}
+
+// Fg returns a copy of s with foreground color c.
+func (s Style) Fg(c Color) Style {
+	s.fg = c
+	return s
+}
+
+// Bg returns a copy of s with background color c.
+func (s Style) Bg(c Color) Style {
+	s.bg = c
+	return s
+}
+
+// With returns a copy of s with the attributes in a enabled.
+func (s Style) With(a Attr) Style {
+	s.attrs |= a
+	return s
+}
+
+// Without returns a copy of s with the attributes in a disabled.
+func (s Style) Without(a Attr) Style {
+	s.attrs &^= a
+	return s
+}
+
+// Bold returns a copy of s with bold text.
+func (s Style) Bold() Style { return s.With(AttrBold) }
+
+// Dim returns a copy of s with dim text.
+func (s Style) Dim() Style { return s.With(AttrDim) }
+
+// Italic returns a copy of s with italic text.
+func (s Style) Italic() Style { return s.With(AttrItalic) }
+
+// Blink returns a copy of s with blinking text.
+func (s Style) Blink() Style { return s.With(AttrBlink) }
+
+// Reverse returns a copy of s with reverse video.
+func (s Style) Reverse() Style { return s.With(AttrReverse) }
+
+// Hidden returns a copy of s with hidden text.
+func (s Style) Hidden() Style { return s.With(AttrHidden) }
+
+// Strikethrough returns a copy of s with crossed-out text.
+func (s Style) Strikethrough() Style { return s.With(AttrStrikethrough) }
+
+// Overline returns a copy of s with a line above the text.
+func (s Style) Overline() Style { return s.With(AttrOverline) }
+
+// Underline returns a copy of s underlined in style u; UnderlineNone
+// removes the underline.
+func (s Style) Underline(u UnderlineStyle) Style {
+	s.underline = u
+	return s
+}
+
+// UnderlineColor returns a copy of s with underline color c.
+func (s Style) UnderlineColor(c Color) Style {
+	s.ulColor = c
+	return s
+}
+
+// Foreground returns the foreground color of s.
+func (s Style) Foreground() Color { return s.fg }
+
+// Background returns the background color of s.
+func (s Style) Background() Color { return s.bg }
+
+// Attrs returns the text attributes of s.
+func (s Style) Attrs() Attr { return s.attrs }
+
+/** Render returns str wrapped in the escape sequences for s, followed by a
+ * reset. Colors are emitted exactly as given; use Terminal.PrintStyled to
+ * have them converted for the terminal's color profile. The default style
+ * returns str unchanged.
+ *
+ * Parameters:
+ *   str (string) — the text to style.
+ *
+ * Returns:
+ *   string — the escaped text.
+ *
+ * Example:
+ *   fmt.Println(termlib.NewStyle().Fg(termlib.Green).Render("ok"))
+ */
+func (s Style) Render(str string) string {
+	if s == defaultStyle {
+		return str
+	}
+	return "\033[" + strings.Join(s.sgrParams(defaultStyle, true), ";") + "m" + str + Reset
+}
+
+// adapt returns s as a terminal with capabilities c can show it: colors
+// are converted for its color profile and attributes it lacks are dropped.
+func (s Style) adapt(c Capabilities) Style {
+	s.fg = s.fg.convert(c.Colors)
+	s.bg = s.bg.convert(c.Colors)
+	s.ulColor = s.ulColor.convert(c.Colors)
+	if !c.Italic {
+		s.attrs &^= AttrItalic
+	}
+	if !c.StyledUnderline {
+		if s.underline != UnderlineNone {
+			s.underline = UnderlineSingle
+		}
+		s.ulColor = DefaultColor
+	}
+	return s
+}
+
+// attrCodes pairs each Attr other than bold and dim with its SGR on and
+// off codes. Bold and dim share an off code and are handled separately.
+var attrCodes = []struct {
+	attr    Attr
+	on, off string
+}{
+	{AttrItalic, "3", "23"},
+	{AttrBlink, "5", "25"},
+	{AttrReverse, "7", "27"},
+	{AttrHidden, "8", "28"},
+	{AttrStrikethrough, "9", "29"},
+	{AttrOverline, "53", "55"},
+}
+
+// sgrParams returns the SGR parameters that switch the pen from "from" to
+// s, or from the reset state when full is true.
+func (s Style) sgrParams(from Style, full bool) []string {
+	var p []string
+	if full {
+		p = append(p, "0")
+		from = defaultStyle
+	}
+	if s.fg != from.fg {
+		p = append(p, s.fg.sgr(false))
+	}
+	if s.bg != from.bg {
+		p = append(p, s.bg.sgr(true))
+	}
+
+	// SGR 22 turns off both bold and dim, so re-enable whichever of the
+	// two should remain after it.
+	const intensity = AttrBold | AttrDim
+	if on, was := s.attrs&intensity, from.attrs&intensity; on != was {
+		if was&^on != 0 {
+			p = append(p, "22")
+			was = 0
+		}
+		if on&AttrBold != 0 && was&AttrBold == 0 {
+			p = append(p, "1")
+		}
+		if on&AttrDim != 0 && was&AttrDim == 0 {
+			p = append(p, "2")
+		}
+	}
+	for _, a := range attrCodes {
+		if s.attrs&a.attr != from.attrs&a.attr {
+			p = append(p, sgrFlag(s.attrs&a.attr != 0, a.on, a.off))
+		}
+	}
+
+	if s.underline != from.underline {
+		switch s.underline {
+		case UnderlineNone:
+			p = append(p, "24")
+		case UnderlineSingle:
+			p = append(p, "4")
+		default:
+			p = append(p, "4:"+strconv.Itoa(int(s.underline)))
+		}
+	}
+	if s.ulColor != from.ulColor {
+		p = append(p, s.ulColor.sgrUnderline())
+	}
+	return p
+}
+
+// sgrFlag returns on or off depending on set.
+func sgrFlag(set bool, on, off string) string {
+	if set {
+		return on
+	}
+	return off
+}
@@ -0,0 +1,120 @@
+// style_test.go — tests for text attributes and SGR generation.
+// Copyright (C) 2025 R. S. Doiel
+package termlib
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestStyleSGRParams(t *testing.T) {
+	cases := []struct {
+		name     string
+		from, to Style
+		full     bool
+		want     string
+	}{
+		{"full reset", Style{}, Style{attrs: AttrBold | AttrReverse}, true, "0;1;7"},
+		{"all attributes", Style{}, Style{attrs: AttrBold | AttrDim | AttrItalic | AttrBlink | AttrReverse | AttrHidden | AttrStrikethrough | AttrOverline}, false, "1;2;3;5;7;8;9;53"},
+		{"bold off keeps dim", Style{attrs: AttrBold | AttrDim}, Style{attrs: AttrDim}, false, "22;2"},
+		{"dim to bold", Style{attrs: AttrDim}, Style{attrs: AttrBold}, false, "22;1"},
+		{"add dim to bold", Style{attrs: AttrBold}, Style{attrs: AttrBold | AttrDim}, false, "2"},
+		{"reverse off", Style{attrs: AttrReverse | AttrItalic}, Style{attrs: AttrItalic}, false, "27"},
+		{"overline off", Style{attrs: AttrOverline}, Style{}, false, "55"},
+		{"single underline", Style{}, Style{underline: UnderlineSingle}, false, "4"},
+		{"curly underline", Style{underline: UnderlineSingle}, Style{underline: UnderlineCurly}, false, "4:3"},
+		{"dotted underline", Style{}, Style{underline: UnderlineDotted}, false, "4:4"},
+		{"underline off", Style{underline: UnderlineDouble}, Style{}, false, "24"},
+		{"underline rgb color", Style{}, Style{underline: UnderlineCurly, ulColor: RGBColor(255, 0, 0)}, false, "4:3;58;2;255;0;0"},
+		{"underline palette color", Style{}, Style{ulColor: Red}, false, "58;5;1"},
+		{"underline color off", Style{ulColor: Red}, Style{}, false, "59"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := strings.Join(c.to.sgrParams(c.from, c.full), ";")
+			if got != c.want {
+				t.Errorf("got %q, want %q", got, c.want)
+			}
+		})
+	}
+}
+
+func TestReverseVideoRow(t *testing.T) {
+	var buf bytes.Buffer
+	term := New(&buf)
+
+	term.SetReverse()
+	term.Print("selected")
+	term.Refresh()
+
+	expected := "\033[H\033[0;7mselected\033[0m"
+	if got := buf.String(); got != expected {
+		t.Errorf("got %q, want %q", got, expected)
+	}
+}
+
+func TestClearAttr(t *testing.T) {
+	var buf bytes.Buffer
+	term := New(&buf)
+
+	term.SetBold()
+	term.SetStrikethrough()
+	term.ClearBold()
+	term.Print("x")
+	term.Refresh()
+
+	expected := "\033[H\033[0;9mx\033[0m"
+	if got := buf.String(); got != expected {
+		t.Errorf("got %q, want %q", got, expected)
+	}
+}
+
+func TestStyleBuilderIsImmutable(t *testing.T) {
+	base := NewStyle().Fg(Yellow).Bold()
+	alert := base.Reverse().Bg(Red)
+
+	if base.Attrs() != AttrBold || base.Background() != DefaultColor {
+		t.Errorf("base modified by chaining: attrs=%v bg=%v", base.Attrs(), base.Background())
+	}
+	if alert.Attrs() != AttrBold|AttrReverse || alert.Foreground() != Yellow || alert.Background() != Red {
+		t.Errorf("alert = attrs %v fg %v bg %v", alert.Attrs(), alert.Foreground(), alert.Background())
+	}
+	if got := alert.Without(AttrBold).Attrs(); got != AttrReverse {
+		t.Errorf("Without(AttrBold) attrs = %v, want %v", got, AttrReverse)
+	}
+}
+
+func TestStyleRender(t *testing.T) {
+	cases := []struct {
+		st   Style
+		want string
+	}{
+		{NewStyle(), "ok"},
+		{NewStyle().Fg(Green), "\033[0;32mok\033[0m"},
+		{NewStyle().Fg(RGBColor(1, 2, 3)).Underline(UnderlineCurly), "\033[0;38;2;1;2;3;4:3mok\033[0m"},
+	}
+	for _, c := range cases {
+		if got := c.st.Render("ok"); got != c.want {
+			t.Errorf("Render() = %q, want %q", got, c.want)
+		}
+	}
+}
+
+func TestPrintStyledKeepsCurrentStyle(t *testing.T) {
+	var buf bytes.Buffer
+	term := New(&buf)
+
+	term.SetFgColor(Blue)
+	term.PrintStyled(NewStyle().Bold(), "B")
+	term.Print("b")
+	term.Refresh()
+
+	expected := "\033[H\033[0;1mB\033[0;34mb\033[0m"
+	if got := buf.String(); got != expected {
+		t.Errorf("got %q, want %q", got, expected)
+	}
+	if got := term.GetStyle(); got != NewStyle().Fg(Blue) {
+		t.Errorf("current style changed by PrintStyled: %+v", got)
+	}
+}
@@ -0,0 +1,89 @@
+// suspend.go — job control for raw-mode applications.
+// Copyright (C) 2025 R. S. Doiel
+package termlib
+
+import "errors"
+
+// suspendStop stops the process; tests replace it.
+var suspendStop = stopProcess
+
+// ErrSuspendUnsupported is returned by Suspend on platforms without job
+// control.
+var ErrSuspendUnsupported = errors.New("suspend is not supported on this platform")
+
+// ErrNotStopped is returned by Suspend when SIGTSTP is ignored, or did not
+// stop the process, for example because its process group is orphaned.
+var ErrNotStopped = errors.New("the process could not be stopped")
+
+// suspendCheck reports why the process cannot be suspended, before any
+// terminal mode is changed.
+func suspendCheck() error {
+	if !jobControl {
+		return ErrSuspendUnsupported
+	}
+	if stopIgnored() {
+		return ErrNotStopped
+	}
+	return nil
+}
+
+/** Suspend stops the process as the shell's Ctrl+Z would, with the
+ * terminal in a state fit for the shell. Raw mode disables the terminal's
+ * own handling of Ctrl+Z, so applications call Suspend when they read
+ * Key(0x1a).
+ *
+ * Every mode termlib has set (see RestoreTerminal) is undone first: the
+ * cursor is shown, mouse reporting turned off, the alternate screen left
+ * and cooked mode restored. The process then sends itself SIGTSTP. When
+ * the shell continues it (fg, SIGCONT) the modes are set again and every
+ * Terminal involved forgets its screen contents, so its next Refresh
+ * repaints in full. Terminal.Suspend does that Refresh for you.
+ *
+ * If SIGTSTP is ignored Suspend returns ErrNotStopped at once, leaving the
+ * terminal as it is. If the signal does not stop the process within a
+ * second, the modes are set again and ErrNotStopped is returned.
+ *
+ * Returns:
+ *   error — ErrSuspendUnsupported on Windows, ErrNotStopped, or the error
+ *           from signalling the process.
+ *
+ * Example:
+ *   case termlib.Key(0x1a): // Ctrl+Z
+ *       termlib.Suspend()
+ *       redraw()
+ */
+func Suspend() error {
+	if err := suspendCheck(); err != nil {
+		return err
+	}
+	list := registeredRestorers()
+	for i := len(list) - 1; i >= 0; i-- {
+		list[i].suspend()
+	}
+	err := suspendStop()
+	for _, r := range list {
+		r.resume()
+	}
+	return err
+}
+
+/** Suspend stops the process like the package-level Suspend and, once it
+ * is continued, repaints the whole screen from t's cell buffer.
+ *
+ * Returns:
+ *   error — as for the package-level Suspend.
+ *
+ * Example:
+ *   if k == termlib.Key(0x1a) {
+ *       term.Suspend()
+ *   }
+ */
+func (t *Terminal) Suspend() error {
+	if err := suspendCheck(); err != nil {
+		return err
+	}
+	err := Suspend()
+	t.Invalidate()
+	t.Refresh()
+	return err
+}
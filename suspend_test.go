@@ -0,0 +1,58 @@
+// suspend_test.go — tests for suspending and resuming.
+// Copyright (C) 2025 R. S. Doiel
+package termlib
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestTerminalSuspend(t *testing.T) {
+	if !jobControl {
+		t.Skip("no job control on this platform")
+	}
+	resetRegistry(t)
+	var out bytes.Buffer
+	term := New(&out)
+	term.SetSize(5, 1)
+	raw := false
+	restore, err := EnterRawMode(rawReader{strings.NewReader(""), &raw})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer restore()
+	term.EnterAltScreen()
+	term.EnableMouse(MouseClicks)
+	term.HideCursor()
+	term.Print("hi")
+	term.Refresh()
+	out.Reset()
+
+	stopped := false
+	defer func(orig func() error) { suspendStop = orig }(suspendStop)
+	suspendStop = func() error {
+		stopped = true
+		if raw || out.String() != "\033[?25h\033[?1000l\033[?1006l\033[?1049l" {
+			t.Errorf("not restored before stopping: raw %v, output %q", raw, out.String())
+		}
+		out.Reset()
+		return nil
+	}
+	if err := term.Suspend(); err != nil {
+		t.Fatal(err)
+	}
+	if !stopped {
+		t.Fatal("process not stopped")
+	}
+	if !raw {
+		t.Error("raw mode not re-entered")
+	}
+	got := out.String()
+	if !strings.HasPrefix(got, "\033[?1049h\033[?1000h\033[?1006h") {
+		t.Errorf("modes not set again: %q", got)
+	}
+	if !strings.Contains(got, "\033[2J") || !strings.Contains(got, "hi") || !strings.Contains(got, "\033[?25l") {
+		t.Errorf("screen not repainted: %q", got)
+	}
+}
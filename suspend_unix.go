@@ -0,0 +1,59 @@
+//go:build !windows
+
+// suspend_unix.go — stopping the process with SIGTSTP.
+// Copyright (C) 2025 R. S. Doiel
+package termlib
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// jobControl reports whether the process can be suspended.
+const jobControl = true
+
+// stopTimeout is how long stopProcess waits for SIGCONT before deciding
+// that SIGTSTP did not stop the process, because it was discarded, as it
+// is for an orphaned process group, or caught by the application. The
+// timer keeps running while the process is stopped, so after a longer
+// stop it has expired too by the time SIGCONT arrives; contGrace is how
+// long SIGCONT is then still waited for.
+var stopTimeout = time.Second
+
+const contGrace = 250 * time.Millisecond
+
+// stopIgnored reports whether SIGTSTP is ignored, as under some job
+// launchers, so that sending it would not stop the process.
+func stopIgnored() bool {
+	return signal.Ignored(syscall.SIGTSTP)
+}
+
+// stopProcess sends SIGTSTP to the process and waits until it has been
+// continued with SIGCONT. It returns ErrNotStopped if the process was not
+// stopped.
+func stopProcess() error {
+	cont := make(chan os.Signal, 1)
+	signal.Notify(cont, syscall.SIGCONT)
+	defer signal.Stop(cont)
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGTSTP); err != nil {
+		return err
+	}
+	timer := time.NewTimer(stopTimeout)
+	defer timer.Stop()
+	select {
+	case <-cont:
+		return nil
+	case <-timer.C:
+	}
+	// SIGCONT takes priority over the expired timer.
+	grace := time.NewTimer(contGrace)
+	defer grace.Stop()
+	select {
+	case <-cont:
+		return nil
+	case <-grace.C:
+		return ErrNotStopped
+	}
+}
@@ -0,0 +1,93 @@
+//go:build !windows
+
+// suspend_unix_test.go — tests for stopping the process with SIGTSTP.
+// Copyright (C) 2025 R. S. Doiel
+package termlib
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestSuspendIgnored(t *testing.T) {
+	resetRegistry(t)
+	signal.Ignore(syscall.SIGTSTP)
+	defer signal.Reset(syscall.SIGTSTP)
+	var out bytes.Buffer
+	term := New(&out)
+	term.EnterAltScreen()
+	out.Reset()
+
+	done := make(chan error, 1)
+	go func() { done <- term.Suspend() }()
+	select {
+	case err := <-done:
+		if err != ErrNotStopped {
+			t.Errorf("Suspend() = %v, want ErrNotStopped", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Suspend blocked with SIGTSTP ignored")
+	}
+	if out.Len() != 0 || !term.InAltScreen() {
+		t.Errorf("terminal changed: %q", out.String())
+	}
+	RestoreTerminal()
+}
+
+func TestSuspendNotStopped(t *testing.T) {
+	resetRegistry(t)
+	// Catching SIGTSTP keeps it from stopping the process, as for an
+	// orphaned process group.
+	caught := make(chan os.Signal, 1)
+	signal.Notify(caught, syscall.SIGTSTP)
+	defer signal.Stop(caught)
+	defer func(d time.Duration) { stopTimeout = d }(stopTimeout)
+	stopTimeout = 50 * time.Millisecond
+
+	var out bytes.Buffer
+	term := New(&out)
+	term.EnterAltScreen()
+	out.Reset()
+	if err := Suspend(); err != ErrNotStopped {
+		t.Errorf("Suspend() = %v, want ErrNotStopped", err)
+	}
+	if got := out.String(); got != "\033[?1049l\033[?1049h" {
+		t.Errorf("modes not set again: %q", got)
+	}
+	RestoreTerminal()
+}
+
+func TestSuspendLongerThanTimeout(t *testing.T) {
+	if os.Getenv("TERMLIB_SUSPEND_TEST") == "1" {
+		stopTimeout = 50 * time.Millisecond
+		if err := Suspend(); err != nil {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	cmd := exec.Command(os.Args[0], "-test.run=^TestSuspendLongerThanTimeout$")
+	cmd.Env = append(os.Environ(), "TERMLIB_SUSPEND_TEST=1")
+	// A process group of its own, whose parent is this process, is not
+	// orphaned, so SIGTSTP stops the child.
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	var ws syscall.WaitStatus
+	if _, err := syscall.Wait4(cmd.Process.Pid, &ws, syscall.WUNTRACED, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !ws.Stopped() {
+		t.Fatalf("child not stopped: %v", ws)
+	}
+	time.Sleep(300 * time.Millisecond) // well past the child's stopTimeout
+	cmd.Process.Signal(syscall.SIGCONT)
+	if err := cmd.Wait(); err != nil {
+		t.Errorf("Suspend reported a failure after a long stop: %v", err)
+	}
+}
@@ -0,0 +1,18 @@
+//go:build windows
+
+// suspend_windows.go — Windows has no job control.
+// Copyright (C) 2025 R. S. Doiel
+package termlib
+
+// jobControl reports whether the process can be suspended.
+const jobControl = false
+
+// stopIgnored is never called on Windows; see jobControl.
+func stopIgnored() bool {
+	return false
+}
+
+// stopProcess is never called on Windows; see jobControl.
+func stopProcess() error {
+	return ErrSuspendUnsupported
+}
@@ -0,0 +1,281 @@
+// terminfo.go — a reader for compiled terminfo entries.
+// Copyright (C) 2025 R. S. Doiel
+package termlib
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// terminfo holds the capabilities of one compiled terminfo entry that
+// Capabilities needs. Standard capabilities are indexed by their position
+// in term.h; absent numbers are -1 and absent strings are "".
+// Extended (user-defined) capabilities are looked up by name.
+type terminfo struct {
+	names      []string
+	bools      []bool
+	nums       []int
+	strs       []string
+	extBools   map[string]bool
+	extNums    map[string]int
+	extStrings map[string]string
+}
+
+// Positions of the standard capabilities used by Capabilities.
+const (
+	tiMaxColors        = 13  // colors
+	tiEnterItalicsMode = 311 // sitm
+	tiKeyMouse         = 355 // kmous
+)
+
+// errBadTerminfo reports a terminfo entry that could not be parsed.
+var errBadTerminfo = errors.New("termlib: malformed terminfo entry")
+
+// num returns standard number capability i, or -1 if it is absent.
+func (ti *terminfo) num(i int) int {
+	if i < len(ti.nums) {
+		return ti.nums[i]
+	}
+	return -1
+}
+
+// str returns standard string capability i, or "" if it is absent.
+func (ti *terminfo) str(i int) string {
+	if i < len(ti.strs) {
+		return ti.strs[i]
+	}
+	return ""
+}
+
+// has reports whether the extended capability name is present, whatever
+// its type.
+func (ti *terminfo) has(name string) bool {
+	_, b := ti.extBools[name]
+	_, n := ti.extNums[name]
+	_, s := ti.extStrings[name]
+	return b || n || s
+}
+
+var (
+	terminfoCacheMu sync.Mutex
+	terminfoCache   = map[string]*terminfo{}
+)
+
+// loadTerminfo finds and parses the compiled terminfo entry for the
+// terminal type name, searching the same places as ncurses. Entries are
+// read once and then cached; a missing entry is reported as an error.
+func loadTerminfo(name string) (*terminfo, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || name[0] == '.' {
+		return nil, fmt.Errorf("termlib: bad terminal type %q", name)
+	}
+	terminfoCacheMu.Lock()
+	defer terminfoCacheMu.Unlock()
+	if ti, ok := terminfoCache[name]; ok {
+		if ti == nil {
+			return nil, os.ErrNotExist
+		}
+		return ti, nil
+	}
+	for _, dir := range terminfoDirs() {
+		// Entries live in a subdirectory named by their first character,
+		// or on macOS by its hexadecimal code.
+		for _, sub := range []string{name[:1], fmt.Sprintf("%02x", name[0])} {
+			data, err := os.ReadFile(filepath.Join(dir, sub, name))
+			if err != nil {
+				continue
+			}
+			ti, err := parseTerminfo(data)
+			if err != nil {
+				return nil, err
+			}
+			terminfoCache[name] = ti
+			return ti, nil
+		}
+	}
+	terminfoCache[name] = nil
+	return nil, os.ErrNotExist
+}
+
+// terminfoDirs returns the directories searched for terminfo entries, in
+// order: $TERMINFO, ~/.terminfo, $TERMINFO_DIRS and the system locations.
+func terminfoDirs() []string {
+	system := []string{"/etc/terminfo", "/lib/terminfo", "/usr/share/terminfo", "/usr/lib/terminfo"}
+	var dirs []string
+	if d := os.Getenv("TERMINFO"); d != "" {
+		dirs = append(dirs, d)
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, ".terminfo"))
+	}
+	if v := os.Getenv("TERMINFO_DIRS"); v != "" {
+		for _, d := range strings.Split(v, ":") {
+			if d == "" { // an empty entry stands for the system locations
+				dirs = append(dirs, system...)
+			} else {
+				dirs = append(dirs, d)
+			}
+		}
+	}
+	return append(dirs, system...)
+}
+
+// parseTerminfo parses a compiled terminfo entry in either the legacy
+// format, with 16-bit numbers, or the 32-bit format of ncurses 6.1,
+// including any extended capabilities that follow the standard ones.
+func parseTerminfo(data []byte) (*terminfo, error) {
+	r := &tiReader{data: data}
+	magic := r.short()
+	numSize := 2
+	switch magic {
+	case 0o432:
+	case 0o1036:
+		numSize = 4
+	default:
+		return nil, errBadTerminfo
+	}
+	namesSize, boolCount, numCount, strCount, tableSize := r.short(), r.short(), r.short(), r.short(), r.short()
+	if r.err != nil || namesSize < 0 || boolCount < 0 || numCount < 0 || strCount < 0 || tableSize < 0 {
+		return nil, errBadTerminfo
+	}
+	ti := &terminfo{
+		extBools:   map[string]bool{},
+		extNums:    map[string]int{},
+		extStrings: map[string]string{},
+	}
+	ti.names = strings.Split(strings.TrimRight(string(r.bytes(namesSize)), "\x00"), "|")
+	for _, b := range r.bytes(boolCount) {
+		ti.bools = append(ti.bools, b == 1)
+	}
+	r.align()
+	for i := 0; i < numCount; i++ {
+		ti.nums = append(ti.nums, r.number(numSize))
+	}
+	offsets := r.shorts(strCount)
+	table := r.bytes(tableSize)
+	if r.err != nil {
+		return nil, errBadTerminfo
+	}
+	ti.strs = make([]string, strCount)
+	for i, off := range offsets {
+		ti.strs[i], _ = tiString(table, off)
+	}
+
+	// Extended capabilities, if present, follow on an even boundary.
+	r.align()
+	if r.pos >= len(data) {
+		return ti, nil
+	}
+	extBools, extNums, extStrs, _, extTableSize := r.short(), r.short(), r.short(), r.short(), r.short()
+	if r.err != nil || extBools < 0 || extNums < 0 || extStrs < 0 || extTableSize < 0 {
+		return nil, errBadTerminfo
+	}
+	boolVals := r.bytes(extBools)
+	r.align()
+	numVals := make([]int, extNums)
+	for i := range numVals {
+		numVals[i] = r.number(numSize)
+	}
+	strOffsets := r.shorts(extStrs)
+	nameOffsets := r.shorts(extBools + extNums + extStrs)
+	table = r.bytes(extTableSize)
+	if r.err != nil {
+		return nil, errBadTerminfo
+	}
+	// The names follow the string values in the table.
+	namesBase := 0
+	for _, off := range strOffsets {
+		if s, ok := tiString(table, off); ok && off+len(s)+1 > namesBase {
+			namesBase = off + len(s) + 1
+		}
+	}
+	name := func(i int) string {
+		s, _ := tiString(table, namesBase+nameOffsets[i])
+		return s
+	}
+	for i, v := range boolVals {
+		ti.extBools[name(i)] = v == 1
+	}
+	for i, v := range numVals {
+		ti.extNums[name(extBools+i)] = v
+	}
+	for i, off := range strOffsets {
+		if s, ok := tiString(table, off); ok {
+			ti.extStrings[name(extBools+extNums+i)] = s
+		}
+	}
+	return ti, nil
+}
+
+// tiString returns the NUL-terminated string at off in table, reporting
+// false for the negative offsets of absent or cancelled capabilities.
+func tiString(table []byte, off int) (string, bool) {
+	if off < 0 || off >= len(table) {
+		return "", false
+	}
+	end := off
+	for end < len(table) && table[end] != 0 {
+		end++
+	}
+	return string(table[off:end]), true
+}
+
+// tiReader reads the little-endian fields of a compiled terminfo entry,
+// recording the first read past the end of the data.
+type tiReader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+// bytes returns the next n bytes.
+func (r *tiReader) bytes(n int) []byte {
+	if r.err != nil || r.pos+n > len(r.data) {
+		r.err = errBadTerminfo
+		return nil
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b
+}
+
+// short returns the next 16-bit signed number.
+func (r *tiReader) short() int {
+	b := r.bytes(2)
+	if b == nil {
+		return -1
+	}
+	return int(int16(binary.LittleEndian.Uint16(b)))
+}
+
+// shorts returns the next n 16-bit signed numbers.
+func (r *tiReader) shorts(n int) []int {
+	v := make([]int, n)
+	for i := range v {
+		v[i] = r.short()
+	}
+	return v
+}
+
+// number returns the next number of the given size in bytes.
+func (r *tiReader) number(size int) int {
+	if size == 2 {
+		return r.short()
+	}
+	b := r.bytes(4)
+	if b == nil {
+		return -1
+	}
+	return int(int32(binary.LittleEndian.Uint32(b)))
+}
+
+// align skips a padding byte to reach an even offset.
+func (r *tiReader) align() {
+	if r.pos%2 == 1 && r.pos < len(r.data) {
+		r.pos++
+	}
+}
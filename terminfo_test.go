@@ -0,0 +1,175 @@
+// terminfo_test.go — tests for reading compiled terminfo entries.
+// Copyright (C) 2025 R. S. Doiel
+package termlib
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// tiEntry describes a terminfo entry for compileTerminfo.
+type tiEntry struct {
+	names   string
+	nums    map[int]int
+	strs    map[int]string
+	extBool []string
+	extStr  [][2]string // name and value
+}
+
+// compileTerminfo encodes e in the compiled terminfo format, with 32-bit
+// numbers when wide is true.
+func compileTerminfo(e tiEntry, wide bool) []byte {
+	var out bytes.Buffer
+	short := func(v int) { binary.Write(&out, binary.LittleEndian, int16(v)) }
+	number := func(v int) {
+		if wide {
+			binary.Write(&out, binary.LittleEndian, int32(v))
+		} else {
+			short(v)
+		}
+	}
+	align := func() {
+		if out.Len()%2 == 1 {
+			out.WriteByte(0)
+		}
+	}
+	numCount, strCount := 0, 0
+	for i := range e.nums {
+		numCount = max(numCount, i+1)
+	}
+	for i := range e.strs {
+		strCount = max(strCount, i+1)
+	}
+	var table bytes.Buffer
+	offsets := make([]int, strCount)
+	for i := range offsets {
+		offsets[i] = -1
+		if s, ok := e.strs[i]; ok {
+			offsets[i] = table.Len()
+			table.WriteString(s + "\x00")
+		}
+	}
+
+	if wide {
+		short(0o1036)
+	} else {
+		short(0o432)
+	}
+	short(len(e.names) + 1)
+	short(1) // one boolean
+	short(numCount)
+	short(strCount)
+	short(table.Len())
+	out.WriteString(e.names + "\x00")
+	out.WriteByte(1)
+	align()
+	for i := 0; i < numCount; i++ {
+		if v, ok := e.nums[i]; ok {
+			number(v)
+		} else {
+			number(-1)
+		}
+	}
+	for _, off := range offsets {
+		short(off)
+	}
+	out.Write(table.Bytes())
+	if len(e.extBool) == 0 && len(e.extStr) == 0 {
+		return out.Bytes()
+	}
+
+	align()
+	var ext bytes.Buffer
+	var valueOffsets, nameOffsets []int
+	names := append([]string{}, e.extBool...)
+	for _, kv := range e.extStr {
+		valueOffsets = append(valueOffsets, ext.Len())
+		ext.WriteString(kv[1] + "\x00")
+		names = append(names, kv[0])
+	}
+	base := ext.Len()
+	for _, name := range names {
+		nameOffsets = append(nameOffsets, ext.Len()-base)
+		ext.WriteString(name + "\x00")
+	}
+	short(len(e.extBool))
+	short(0)
+	short(len(e.extStr))
+	short(len(valueOffsets) + len(nameOffsets))
+	short(ext.Len())
+	for range e.extBool {
+		out.WriteByte(1)
+	}
+	align()
+	for _, off := range append(valueOffsets, nameOffsets...) {
+		short(off)
+	}
+	out.Write(ext.Bytes())
+	return out.Bytes()
+}
+
+func TestParseTerminfo(t *testing.T) {
+	entry := tiEntry{
+		names:   "xtest|test terminal",
+		nums:    map[int]int{0: 80, tiMaxColors: 256},
+		strs:    map[int]string{0: "\a", tiEnterItalicsMode: "\033[3m"},
+		extBool: []string{"AX", "RGB"},
+		extStr:  [][2]string{{"Smulx", "\033[4:%p1%dm"}, {"Ss", "\033[%p1%d q"}},
+	}
+	for _, wide := range []bool{false, true} {
+		ti, err := parseTerminfo(compileTerminfo(entry, wide))
+		if err != nil {
+			t.Fatalf("wide %v: %v", wide, err)
+		}
+		if len(ti.names) != 2 || ti.names[0] != "xtest" {
+			t.Errorf("names: %q", ti.names)
+		}
+		if ti.num(tiMaxColors) != 256 || ti.num(1) != -1 || ti.num(1000) != -1 {
+			t.Errorf("numbers: %v", ti.nums)
+		}
+		if ti.str(tiEnterItalicsMode) != "\033[3m" || ti.str(tiKeyMouse) != "" {
+			t.Errorf("strings: sitm %q, kmous %q", ti.str(tiEnterItalicsMode), ti.str(tiKeyMouse))
+		}
+		if !ti.extBools["RGB"] || ti.extStrings["Ss"] != "\033[%p1%d q" || !ti.has("Smulx") || ti.has("Sync") {
+			t.Errorf("extended: %v %q", ti.extBools, ti.extStrings)
+		}
+	}
+}
+
+func TestParseTerminfoMalformed(t *testing.T) {
+	good := compileTerminfo(tiEntry{names: "x", strs: map[int]string{0: "a"}}, false)
+	for _, data := range [][]byte{nil, {0x1a}, {0, 0, 0, 0}, good[:len(good)-3]} {
+		if _, err := parseTerminfo(data); !errors.Is(err, errBadTerminfo) {
+			t.Errorf("parseTerminfo(%q): got %v", data, err)
+		}
+	}
+}
+
+func TestLoadTerminfo(t *testing.T) {
+	dir := t.TempDir()
+	for _, sub := range []string{"x", "78"} { // Linux and macOS layouts
+		os.MkdirAll(filepath.Join(dir, sub), 0o755)
+	}
+	os.WriteFile(filepath.Join(dir, "x", "xtest-load"), compileTerminfo(tiEntry{names: "xtest-load", nums: map[int]int{tiMaxColors: 8}}, false), 0o644)
+	os.WriteFile(filepath.Join(dir, "78", "xtest-hex"), compileTerminfo(tiEntry{names: "xtest-hex", nums: map[int]int{tiMaxColors: 88}}, true), 0o644)
+	t.Setenv("TERMINFO", dir)
+
+	for name, colors := range map[string]int{"xtest-load": 8, "xtest-hex": 88} {
+		ti, err := loadTerminfo(name)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if ti.num(tiMaxColors) != colors {
+			t.Errorf("%s: colors %d", name, ti.num(tiMaxColors))
+		}
+	}
+	for _, name := range []string{"xtest-missing", "", "../x/xtest-load"} {
+		if _, err := loadTerminfo(name); err == nil {
+			t.Errorf("loadTerminfo(%q) succeeded", name)
+		}
+	}
+}
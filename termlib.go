@@ -6,55 +6,65 @@ import (
 	"bytes"
 	"fmt"
 	"io"
-	"os"
 	"sync"
+	"unicode/utf8"
+)
 
-	"golang.org/x/term"
+// ANSI escape codes for styles. Colors are typed values, see Color.
+const (
+	Reset  = "\033[0m"
+	Bold   = "\033[1m"
+	Italic = "\033[3m"
 )
 
-// ANSI escape codes for colors and styles
+// Begin and end a synchronized update (DEC mode 2026): the terminal shows
+// everything written in between at once.
 const (
-	Reset      = "\033[0m"
-	Bold       = "\033[1m"
-	Italic     = "\033[3m"
-	Black      = "\033[30m"
-	Red        = "\033[31m"
-	Green      = "\033[32m"
-	Yellow     = "\033[33m"
-	Blue       = "\033[34m"
-	Magenta    = "\033[35m"
-	Cyan       = "\033[36m"
-	White      = "\033[37m"
-	BlackBg    = "\033[40m"
-	RedBg      = "\033[41m"
-	GreenBg    = "\033[42m"
-	YellowBg   = "\033[43m"
-	BlueBg     = "\033[44m"
-	MagentaBg  = "\033[45m"
-	CyanBg     = "\033[46m"
-	WhiteBg    = "\033[47m"
+	syncBegin = "\033[?2026h"
+	syncEnd   = "\033[?2026l"
 )
 
 // Terminal represents a terminal controller.
-// Output is accumulated in an internal buffer and written to the
-// underlying destination only when Refresh is called, which prevents
-// the terminal from rendering partial frames and eliminates flicker.
+// Drawing operations write into a back buffer of cells. Refresh compares
+// it with a front buffer holding what is already on screen and sends only
+// the cursor moves, style changes and characters needed to bring the
+// screen up to date, in a single write. Unchanged cells cost nothing, and
+// the terminal never renders a partial frame.
 type Terminal struct {
 	mu             sync.Mutex
 	cursorRow      int
 	cursorCol      int
 	terminalWidth  int
 	terminalHeight int
-	fgColor        string
-	bgColor        string
-	isBold         bool
-	isItalic       bool
-	out            io.Writer  // underlying destination (e.g. os.Stdout)
+	pen            Style     // style used by Print
+	out            io.Writer // underlying destination (e.g. os.Stdout)
 	buf            bytes.Buffer
-	styleApplied   bool
+	styles         []Style  // saved by PushStyle, restored by PopStyle
+	front          grid     // what the physical screen currently shows
+	back           grid     // the frame being drawn
+	render         renderer // physical cursor and pen state
+	cursorHidden   bool     // requested cursor visibility
+	termCurHidden  bool     // cursor visibility last sent to the terminal
+	cursorShape    CursorShape
+	termCurShape   CursorShape // cursor shape last sent to the terminal
+	cursorColor    Color
+	termCurColor   Color // cursor color last sent to the terminal
+	clearPending   bool  // erase the physical screen on the next Refresh
+	drawn          bool  // at least one frame has been written
+	caps           Capabilities
+	output         OutputMode    // how Refresh renders the cell buffer
+	sizeFd         int           // descriptor queried for the window size; -1 if none
+	mouseMode      MouseMode     // mouse reporting enabled with EnableMouse
+	altScreen      bool          // the alternate screen is in use
+	kittyFlags     KeyboardFlags // kitty keyboard mode pushed by EnableKittyKeyboard
+	restoreEntry   *restorer     // registered with RestoreTerminal while modes are set
+	err            error         // first write error; nothing is written after it
 }
 
 // New creates a new Terminal instance with the specified writer and default styles.
+// The capabilities are detected from the environment; see
+// DetectCapabilities. Output is fully rendered, with escape sequences,
+// until SetOutputMode chooses otherwise.
 func New(writer io.Writer) *Terminal {
 	t := &Terminal{
 		out:            writer,
@@ -62,20 +72,52 @@ func New(writer io.Writer) *Terminal {
 		cursorCol:      1,
 		terminalWidth:  80,
 		terminalHeight: 24,
-		fgColor:        Reset,
-		bgColor:        Reset,
+		caps:           DetectCapabilities(),
+		sizeFd:         -1,
 	}
+	if f, ok := writer.(interface{ Fd() uintptr }); ok {
+		t.sizeFd = int(f.Fd())
+	}
+	t.render.out = &t.buf
+	t.render.caps = t.caps
 	t.UpdateTerminalSize()
+	t.front = newGrid(t.terminalWidth, t.terminalHeight)
+	t.back = newGrid(t.terminalWidth, t.terminalHeight)
 	return t
 }
 
-// UpdateTerminalSize updates the terminal width and height.
+// UpdateTerminalSize re-reads the window size of the terminal the writer
+// passed to New is attached to. Writers that are not files keep their
+// current size; use SetSize for those. When the size changes the cell
+// buffers are resized and the next Refresh repaints the whole screen.
 func (t *Terminal) UpdateTerminalSize() {
-	width, height, err := term.GetSize(int(os.Stdout.Fd()))
-	if err == nil {
-		t.terminalWidth = width
-		t.terminalHeight = height
+	t.resizeFromTerminal()
+}
+
+// SetSize sets the terminal width and height, for example from the window
+// size reported by an SSH client. Sizes below 1×1 are ignored.
+func (t *Terminal) SetSize(width, height int) {
+	if width < 1 || height < 1 {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.setSize(width, height)
+}
+
+// setSize is SetSize without locking. It reports whether the size changed.
+func (t *Terminal) setSize(width, height int) bool {
+	if width == t.terminalWidth && height == t.terminalHeight {
+		return false
 	}
+	t.terminalWidth = width
+	t.terminalHeight = height
+	t.front.resize(width, height)
+	t.back.resize(width, height)
+	if t.drawn {
+		t.invalidate()
+	}
+	return true
 }
 
 // GetTerminalWidth returns the terminal width.
@@ -97,64 +139,111 @@ func (t *Terminal) Move(row, col int) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 	t.cursorRow, t.cursorCol = row, col
-	fmt.Fprintf(&t.buf, "\033[%d;%dH", row, col)
 }
 
-// Clear clears the screen and moves the cursor to the top-left corner.
+// Clear blanks every cell and moves the cursor to the top-left corner.
+// Before the first frame has been written the physical screen is also
+// erased, removing whatever was there before the program started.
 func (t *Terminal) Clear() {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 	t.cursorRow, t.cursorCol = 1, 1
-	fmt.Fprint(&t.buf, "\033[2J\033[H")
+	t.back.fill(blankCell)
+	if !t.drawn {
+		t.clearPending = true
+	}
 }
 
 // ClrToEOL clears from the current cursor position to the end of the line.
 func (t *Terminal) ClrToEOL() {
 	t.mu.Lock()
 	defer t.mu.Unlock()
-	fmt.Fprint(&t.buf, "\033[0K")
+	t.back.fillRow(t.cursorRow, t.cursorCol, t.back.width, blankCell)
 }
 
 // ClrToBOL clears from the current cursor position to the start of the line.
 func (t *Terminal) ClrToBOL() {
 	t.mu.Lock()
 	defer t.mu.Unlock()
-	fmt.Fprint(&t.buf, "\033[1K")
+	t.back.fillRow(t.cursorRow, 1, t.cursorCol, blankCell)
 }
 
-// HideCursor suppresses the terminal cursor during redraws to eliminate flicker.
-// Always pair with ShowCursor before calling Refresh.
+// HideCursor hides the terminal cursor from the next Refresh onward.
+// The cursor is hidden before that frame's cells are written, so it does
+// not flicker across the screen while they are drawn on terminals without
+// synchronized output, which show a frame as it arrives.
 func (t *Terminal) HideCursor() {
 	t.mu.Lock()
 	defer t.mu.Unlock()
-	fmt.Fprint(&t.buf, "\033[?25l")
+	t.cursorHidden = true
 }
 
-// ShowCursor restores the terminal cursor after a redraw.
+// ShowCursor makes the cursor visible again at the next Refresh, after the
+// frame has been drawn and the cursor placed at its final position.
 func (t *Terminal) ShowCursor() {
 	t.mu.Lock()
 	defer t.mu.Unlock()
-	fmt.Fprint(&t.buf, "\033[?25h")
+	t.cursorHidden = false
 }
 
-// Print writes a string to the terminal with current style and updates the cursor position.
+// Invalidate forgets what is on the physical screen so that the next
+// Refresh erases it and repaints every cell. Use it after other output
+// has been written to the terminal behind the Terminal's back.
+func (t *Terminal) Invalidate() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.invalidate()
+}
+
+// invalidate is Invalidate without locking.
+func (t *Terminal) invalidate() {
+	t.clearPending = true
+	t.render.row, t.render.col = 0, 0
+	t.render.styleKnown = false
+}
+
+// Print writes a string into the cell buffer at the cursor with the current
+// style and advances the cursor. The style stays in effect for later calls
+// until it is changed, reset or popped. The cursor advances by display
+// columns: wide characters such as CJK ideographs and emoji take two, and
+// combining marks join the character before them. A newline moves to
+// column 1 of the next row and a tab to the next eight-column tab stop;
+// text falling outside the screen is clipped.
 func (t *Terminal) Print(s string) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
-	if t.styleApplied {
-		t.applyStyle()
-		fmt.Fprint(&t.buf, s)
-		fmt.Fprint(&t.buf, Reset)
-		t.resetStyleState()
-	} else {
-		fmt.Fprint(&t.buf, s)
-	}
-	for _, c := range s {
-		if c == '\n' {
+	st := t.pen
+	for s != "" {
+		g, w := nextGrapheme(s)
+		s = s[len(g):]
+		r, _ := utf8.DecodeRuneInString(g)
+		switch {
+		case r == '\n' || g == "\r\n":
 			t.cursorRow++
 			t.cursorCol = 1
-		} else {
-			t.cursorCol++
+		case r == '\r':
+			t.cursorCol = 1
+		case r == '\t':
+			next := (t.cursorCol-1)/8*8 + 9
+			for t.cursorCol < next {
+				t.back.put(t.cursorRow, t.cursorCol, " ", 1, st)
+				t.cursorCol++
+			}
+		case r < 0x20 || r == 0x7f:
+			// Other control characters would corrupt the cell model.
+		case w == 0:
+			// A mark with no base character of its own joins the cell
+			// to the left.
+			p := t.back.at(t.cursorRow, t.cursorCol-1)
+			if p != nil && p.width == 0 {
+				p = t.back.at(t.cursorRow, t.cursorCol-2)
+			}
+			if p != nil {
+				p.ch += g
+			}
+		default:
+			t.back.put(t.cursorRow, t.cursorCol, g, w, st)
+			t.cursorCol += w
 		}
 	}
 }
@@ -181,95 +270,313 @@ func (t *Terminal) GetCurPos() (int, int) {
 	return t.cursorRow, t.cursorCol
 }
 
-// Refresh flushes the internal output buffer to the underlying writer in a single
-// write, so the terminal receives a complete frame at once rather than incremental
-// updates. Call Refresh once at the end of every full redraw.
-func (t *Terminal) Refresh() {
+// Refresh brings the screen up to date with the cell buffer. Only cells that
+// differ from the previous frame are sent, together with the minimal cursor
+// moves and style changes between them, and the whole frame goes out in a
+// single write. On terminals with synchronized output (see
+// Capabilities.SyncOutput) the frame is also marked as one update, so it is
+// never shown half drawn even when the write reaches the terminal in
+// pieces. Call Refresh once at the end of every redraw. In
+// OutputLines mode the changed rows are written as plain lines instead
+// (see SetOutputMode).
+//
+// Refresh returns the error that made a write to the output fail, such as
+// a closed connection. The error is sticky: once a write has failed,
+// nothing more is written and every later Refresh returns the same error
+// (see Err), so a render loop can simply stop when Refresh fails.
+func (t *Terminal) Refresh() error {
 	t.mu.Lock()
 	defer t.mu.Unlock()
-	if t.buf.Len() == 0 {
-		return
+	if t.err != nil {
+		return t.err
+	}
+	if t.output == OutputLines {
+		t.refreshLines()
+	} else {
+		t.refreshScreen()
+	}
+	if t.buf.Len() > 0 {
+		t.write(t.buf.Bytes())
+		t.buf.Reset()
 	}
-	t.out.Write(t.buf.Bytes()) //nolint:errcheck
-	t.buf.Reset()
+	return t.err
 }
 
-// GetFgColor retrives the foreground color code.
-func (t *Terminal) GetFgColor() string {
+// write sends b to the output. A write that accepts part of b with no
+// error, or with io.ErrShortWrite, is retried for as long as the writer
+// makes progress; any other error is kept in t.err, even if some bytes
+// were written, after which nothing more is written. t.mu must be held.
+func (t *Terminal) write(b []byte) {
+	for len(b) > 0 && t.err == nil {
+		n, err := t.out.Write(b)
+		b = b[n:]
+		switch {
+		case err == io.ErrShortWrite && n > 0:
+			// Only part was accepted, and nothing lost: send the rest.
+		case err != nil:
+			t.err = err
+		case n == 0 && len(b) > 0:
+			t.err = io.ErrShortWrite
+		}
+	}
+}
+
+// Err returns the error that made a write to the output fail, or nil. Once
+// set it does not change, and the Terminal writes nothing more: Refresh
+// and mode changes such as EnableMouse do nothing.
+func (t *Terminal) Err() error {
 	t.mu.Lock()
 	defer t.mu.Unlock()
-	return t.fgColor
+	return t.err
 }
 
-// GetBgColor retrieves the background color code.
-func (t *Terminal) GetBgColor() string {
+// refreshScreen writes the cursor-addressed changes that bring the screen
+// up to date with the cell buffer into t.buf. t.mu must be held.
+func (t *Terminal) refreshScreen() {
+	r := &t.render
+	sync := t.caps.SyncOutput
+	if sync {
+		t.buf.WriteString(syncBegin)
+	}
+	if t.clearPending {
+		r.setStyle(defaultStyle)
+		t.buf.WriteString("\033[2J")
+		t.front.fill(blankCell)
+		t.clearPending = false
+	}
+	if t.cursorHidden && !t.termCurHidden {
+		t.buf.WriteString("\033[?25l")
+		t.termCurHidden = true
+	}
+	t.buf.WriteString(t.cursorLookSeq())
+	r.diff(&t.front, &t.back)
+	if r.styleKnown && r.style != defaultStyle {
+		// Never leave the terminal styled between frames.
+		r.setStyle(defaultStyle)
+	}
+	if !t.cursorHidden {
+		row := clamp(t.cursorRow, 1, t.back.height)
+		col := clamp(t.cursorCol, 1, t.back.width)
+		r.moveTo(&t.back, row, col)
+		if t.termCurHidden {
+			t.buf.WriteString("\033[?25h")
+			t.termCurHidden = false
+		}
+	}
+	t.drawn = true
+	t.trackModes()
+	if sync {
+		if t.buf.Len() == len(syncBegin) {
+			t.buf.Reset() // nothing changed
+		} else {
+			t.buf.WriteString(syncEnd)
+		}
+	}
+}
+
+// GetFgColor retrieves the foreground color.
+func (t *Terminal) GetFgColor() Color {
 	t.mu.Lock()
 	defer t.mu.Unlock()
-	return t.bgColor
+	return t.pen.fg
+}
+
+// GetBgColor retrieves the background color.
+func (t *Terminal) GetBgColor() Color {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.pen.bg
 }
 
 // SetFgColor sets the foreground color.
-func (t *Terminal) SetFgColor(color string) {
+func (t *Terminal) SetFgColor(color Color) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
-	t.fgColor = color
-	t.styleApplied = true
+	t.pen.fg = color
 }
 
 // SetBgColor sets the background color.
-func (t *Terminal) SetBgColor(color string) {
+func (t *Terminal) SetBgColor(color Color) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
-	t.bgColor = color
-	t.styleApplied = true
+	t.pen.bg = color
 }
 
-// SetBold enables bold text.
-func (t *Terminal) SetBold() {
+// SetAttr enables the text attributes in a.
+func (t *Terminal) SetAttr(a Attr) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
-	t.isBold = true
-	t.styleApplied = true
+	t.pen.attrs |= a
 }
 
+// ClearAttr disables the text attributes in a.
+func (t *Terminal) ClearAttr(a Attr) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.pen.attrs &^= a
+}
+
+// SetBold enables bold text.
+func (t *Terminal) SetBold() { t.SetAttr(AttrBold) }
+
+// ClearBold disables bold text.
+func (t *Terminal) ClearBold() { t.ClearAttr(AttrBold) }
+
+// SetDim enables dim (faint) text.
+func (t *Terminal) SetDim() { t.SetAttr(AttrDim) }
+
+// ClearDim disables dim text.
+func (t *Terminal) ClearDim() { t.ClearAttr(AttrDim) }
+
 // SetItalic enables italic text.
-func (t *Terminal) SetItalic() {
+func (t *Terminal) SetItalic() { t.SetAttr(AttrItalic) }
+
+// ClearItalic disables italic text.
+func (t *Terminal) ClearItalic() { t.ClearAttr(AttrItalic) }
+
+// SetBlink enables blinking text.
+func (t *Terminal) SetBlink() { t.SetAttr(AttrBlink) }
+
+// ClearBlink disables blinking text.
+func (t *Terminal) ClearBlink() { t.ClearAttr(AttrBlink) }
+
+// SetReverse enables reverse video, swapping foreground and background.
+// This is the simplest way to highlight a selected row without choosing
+// explicit colors.
+func (t *Terminal) SetReverse() { t.SetAttr(AttrReverse) }
+
+// ClearReverse disables reverse video.
+func (t *Terminal) ClearReverse() { t.ClearAttr(AttrReverse) }
+
+// SetHidden enables hidden (invisible) text.
+func (t *Terminal) SetHidden() { t.SetAttr(AttrHidden) }
+
+// ClearHidden disables hidden text.
+func (t *Terminal) ClearHidden() { t.ClearAttr(AttrHidden) }
+
+// SetStrikethrough enables crossed-out text.
+func (t *Terminal) SetStrikethrough() { t.SetAttr(AttrStrikethrough) }
+
+// ClearStrikethrough disables crossed-out text.
+func (t *Terminal) ClearStrikethrough() { t.ClearAttr(AttrStrikethrough) }
+
+// SetOverline enables a line above the text.
+func (t *Terminal) SetOverline() { t.SetAttr(AttrOverline) }
+
+// ClearOverline disables the line above the text.
+func (t *Terminal) ClearOverline() { t.ClearAttr(AttrOverline) }
+
+// SetUnderline enables a single underline.
+func (t *Terminal) SetUnderline() { t.SetUnderlineStyle(UnderlineSingle) }
+
+// ClearUnderline disables underlining.
+func (t *Terminal) ClearUnderline() { t.SetUnderlineStyle(UnderlineNone) }
+
+// SetUnderlineStyle selects the underline style; UnderlineNone disables it.
+func (t *Terminal) SetUnderlineStyle(u UnderlineStyle) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
-	t.isItalic = true
-	t.styleApplied = true
+	t.pen.underline = u
 }
 
-// ResetStyle resets all styles to default.
+// SetUnderlineColor sets the underline color (SGR 58); DefaultColor makes
+// the underline follow the text color.
+func (t *Terminal) SetUnderlineColor(color Color) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.pen.ulColor = color
+}
+
+// GetColorProfile returns the color depth colors are rendered at.
+func (t *Terminal) GetColorProfile() ColorProfile {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.caps.Colors
+}
+
+// SetColorProfile overrides the color depth detected by New. Colors richer
+// than the profile are converted to the nearest supported color, and the
+// next Refresh repaints the screen with the new conversion.
+func (t *Terminal) SetColorProfile(p ColorProfile) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	c := t.caps
+	c.Colors = p
+	t.setCapabilities(c)
+}
+
+// ResetStyle resets all styles to default. Styles saved with PushStyle
+// are kept.
 func (t *Terminal) ResetStyle() {
 	t.mu.Lock()
 	defer t.mu.Unlock()
-	fmt.Fprint(&t.buf, Reset)
-	t.resetStyleState()
+	t.pen = defaultStyle
 }
 
-// applyStyle applies the current style settings.
-func (t *Terminal) applyStyle() {
-	if t.fgColor != Reset {
-		fmt.Fprint(&t.buf, t.fgColor)
-	}
-	if t.bgColor != Reset {
-		fmt.Fprint(&t.buf, t.bgColor)
-	}
-	if t.isBold {
-		fmt.Fprint(&t.buf, Bold)
-	}
-	if t.isItalic {
-		fmt.Fprint(&t.buf, Italic)
-	}
+// GetStyle returns the current style.
+func (t *Terminal) GetStyle() Style {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.pen
+}
+
+// SetStyle replaces the current style with st.
+func (t *Terminal) SetStyle(st Style) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.pen = st
+}
+
+// PrintStyled prints s in style st without changing the current style.
+func (t *Terminal) PrintStyled(st Style, s string) {
+	t.WithStyle(st, func() { t.Print(s) })
 }
 
-// resetStyleState resets the internal style state.
-func (t *Terminal) resetStyleState() {
-	t.fgColor = Reset
-	t.bgColor = Reset
-	t.isBold = false
-	t.isItalic = false
-	t.styleApplied = false
+// WithStyle runs fn with st as the current style and restores the previous
+// style afterwards, even if fn panics.
+func (t *Terminal) WithStyle(st Style, fn func()) {
+	t.PushStyle()
+	defer t.PopStyle()
+	t.SetStyle(st)
+	fn()
 }
 
+// PushStyle saves the current style so a later PopStyle can restore it.
+// Changes made between the two apply to every Print in that scope, and
+// scopes may be nested.
+//
+//	term.SetFgColor(termlib.Blue)
+//	term.PushStyle()
+//	term.SetBold()
+//	term.Print("bold blue ")
+//	term.PopStyle()
+//	term.Print("plain blue")
+func (t *Terminal) PushStyle() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.styles = append(t.styles, t.pen)
+}
+
+// PopStyle restores the style saved by the matching PushStyle. With
+// nothing left to pop the style is reset to default.
+func (t *Terminal) PopStyle() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if n := len(t.styles); n > 0 {
+		t.pen = t.styles[n-1]
+		t.styles = t.styles[:n-1]
+		return
+	}
+	t.pen = defaultStyle
+}
+
+// clamp limits v to the range [lo, hi].
+func clamp(v, lo, hi int) int {
+	if v > hi {
+		v = hi
+	}
+	if v < lo {
+		v = lo
+	}
+	return v
+}
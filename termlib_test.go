@@ -19,7 +19,9 @@ package termlib
 import (
 	"fmt"
 	"bytes"
+	"io"
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -28,10 +30,15 @@ func TestClrToBOL(t *testing.T) {
 	var buf bytes.Buffer
 	term := New(&buf)
 
+	term.Move(3, 1)
+	term.Print("0123456789")
+	term.Refresh()
+	buf.Reset()
+
 	term.Move(3, 5)
 	term.ClrToBOL()
 	term.Refresh()
-	expected := "\033[3;5H\033[1K"
+	expected := "\033[3;1H     \033[3;5H"
 	got := buf.String()
 	if got != expected {
 		t.Errorf("ClrToBOL() = %q, want %q", got, expected)
@@ -49,7 +56,7 @@ func TestStyleAndColor(t *testing.T) {
 	term.Print("Styled")
 	term.Refresh()
 
-	expected := "\033[31m\033[43m\033[1m\033[3mStyled\033[0m"
+	expected := "\033[H\033[0;31;43;1;3mStyled\033[0m"
 	got := buf.String()
 	if got != expected {
 		t.Errorf("Style and color output mismatch: got %q, want %q", got, expected)
@@ -75,9 +82,18 @@ func TestTerminal(t *testing.T) {
 	term.Print("Hello")
 	term.ClrToEOL()
 	term.Refresh()
-	expected := "\033[5;10H\033[2J\033[HHello\033[0K"
+	expected := "\033[0m\033[2J\033[HHello"
 	got := buf.String()
 	if got != expected {
+		t.Errorf("first frame = %q, want %q", got, expected)
+	}
+
+	buf.Reset()
+	term.Move(1, 3)
+	term.ClrToEOL()
+	term.Refresh()
+	expected = "\033[1;3H\033[K"
+	if got := buf.String(); got != expected {
 		t.Errorf("ClrToEOL() = %q, want %q", got, expected)
 	}
 }
@@ -94,7 +110,7 @@ func TestResetStyle(t *testing.T) {
 	term.Print("Normal")
 	term.Refresh()
 
-	expected := "\033[0mNormal"
+	expected := "\033[H\033[0mNormal"
 	got := buf.String()
 	if got != expected {
 		t.Errorf("ResetStyle() output mismatch: got %q, want %q", got, expected)
@@ -111,7 +127,7 @@ func TestMultiplePrints(t *testing.T) {
 	term.Print("Second")
 	term.Refresh()
 
-	expected := "\033[34mFirst \033[0m\033[32mSecond\033[0m"
+	expected := "\033[H\033[0;34mFirst \033[32mSecond\033[0m"
 	got := buf.String()
 	if got != expected {
 		t.Errorf("Multiple prints output mismatch: got %q, want %q", got, expected)
@@ -121,7 +137,7 @@ func TestMultiplePrints(t *testing.T) {
 func TestPrintf(t *testing.T) {
 	var buf bytes.Buffer
 	format := "Hello %q %d,\n"
-	expected := fmt.Sprintf(format, "Robert", 63)
+	expected := "\033[H\033[0m" + strings.TrimSuffix(fmt.Sprintf(format, "Robert", 63), "\n") + "\033[2;1H"
 	term := New(&buf)
 	term.Printf(format, "Robert", 63)
 	term.Refresh()
@@ -134,7 +150,7 @@ func TestPrintf(t *testing.T) {
 func TestPrintln(t *testing.T) {
 	const name, age = "Kim", 22
 	var buf bytes.Buffer
-	expected := fmt.Sprintln(name, "is", age, "years old.")
+	expected := "\033[H\033[0m" + fmt.Sprint(name, " is ", age, " years old.") + "\033[2;1H"
 	term := New(&buf)
 	term.Println(name, "is", age, "years old.")
 	term.Refresh()
@@ -144,3 +160,272 @@ func TestPrintln(t *testing.T) {
 	}
 }
 
+func TestRefreshSendsOnlyChanges(t *testing.T) {
+	var buf bytes.Buffer
+	term := New(&buf)
+
+	term.Move(2, 1)
+	term.Print("status: ok   ")
+	term.Refresh()
+	buf.Reset()
+
+	// Nothing changed, nothing is sent.
+	term.Refresh()
+	if got := buf.String(); got != "" {
+		t.Errorf("unchanged frame = %q, want empty", got)
+	}
+
+	// Redrawing the whole line with one different word sends only that word.
+	term.Move(2, 1)
+	term.Print("status: bad  ")
+	term.Refresh()
+	expected := "\033[2;9Hbad  "
+	if got := buf.String(); got != expected {
+		t.Errorf("changed frame = %q, want %q", got, expected)
+	}
+}
+
+func TestRefreshShortGapReprinted(t *testing.T) {
+	var buf bytes.Buffer
+	term := New(&buf)
+
+	term.Print("abcdef")
+	term.Refresh()
+	buf.Reset()
+
+	// Changing columns 1 and 4 is cheaper to send as "XbcY" than as a
+	// cursor move between the two cells; likewise "ef" on the way to the
+	// cursor position.
+	term.Move(1, 1)
+	term.Print("XbcYef")
+	term.Refresh()
+	expected := "\033[HXbcYef"
+	if got := buf.String(); got != expected {
+		t.Errorf("Refresh() = %q, want %q", got, expected)
+	}
+}
+
+func TestRefreshStyleChangeOnly(t *testing.T) {
+	var buf bytes.Buffer
+	term := New(&buf)
+
+	term.Print("ab")
+	term.Refresh()
+	buf.Reset()
+
+	term.Move(1, 2)
+	term.SetFgColor(Red)
+	term.Print("b")
+	term.Refresh()
+	expected := "\033[1;2H\033[31mb\033[0m"
+	if got := buf.String(); got != expected {
+		t.Errorf("Refresh() = %q, want %q", got, expected)
+	}
+}
+
+func TestRefreshSynchronized(t *testing.T) {
+	var buf bytes.Buffer
+	term := New(&buf)
+	caps := term.Capabilities()
+	caps.SyncOutput = true
+	term.SetCapabilities(caps)
+
+	term.Print("ab")
+	term.Refresh()
+	expected := "\033[?2026h\033[H\033[0mab\033[?2026l"
+	if got := buf.String(); got != expected {
+		t.Errorf("first frame = %q, want %q", got, expected)
+	}
+
+	// An unchanged frame sends no empty update.
+	buf.Reset()
+	term.Refresh()
+	if got := buf.String(); got != "" {
+		t.Errorf("unchanged frame = %q, want empty", got)
+	}
+}
+
+// chunkWriter accepts at most n bytes per Write, reporting the rest as a
+// short write, and fails every Write once closed, or with failing set,
+// after accepting part of it.
+type chunkWriter struct {
+	bytes.Buffer
+	n       int
+	closed  bool
+	failing bool
+}
+
+func (w *chunkWriter) Write(p []byte) (int, error) {
+	switch {
+	case w.closed:
+		return 0, io.ErrClosedPipe
+	case w.failing:
+		w.Buffer.Write(p[:min(w.n, len(p))])
+		return min(w.n, len(p)), io.ErrUnexpectedEOF
+	case len(p) > w.n:
+		w.Buffer.Write(p[:w.n])
+		return w.n, io.ErrShortWrite
+	}
+	return w.Buffer.Write(p)
+}
+
+func TestRefreshWriteErrors(t *testing.T) {
+	w := &chunkWriter{n: 3}
+	term := New(w)
+
+	// Short writes are retried until the whole frame is out.
+	term.Print("hello")
+	if err := term.Refresh(); err != nil {
+		t.Fatalf("Refresh() = %v", err)
+	}
+	expected := "\033[H\033[0mhello"
+	if got := w.String(); got != expected {
+		t.Errorf("frame = %q, want %q", got, expected)
+	}
+
+	// A failed write is reported and sticks.
+	w.closed = true
+	term.Print("x")
+	if err := term.Refresh(); err != io.ErrClosedPipe {
+		t.Errorf("Refresh() = %v, want %v", err, io.ErrClosedPipe)
+	}
+	w.closed = false
+	w.Reset()
+	term.EnableBracketedPaste()
+	if err := term.Refresh(); err != io.ErrClosedPipe || term.Err() != io.ErrClosedPipe {
+		t.Errorf("error not kept: %v, %v", err, term.Err())
+	}
+	if got := w.String(); got != "" {
+		t.Errorf("written after the error: %q", got)
+	}
+
+	// A write failing part way is not retried.
+	w = &chunkWriter{n: 3, failing: true}
+	term = New(w)
+	term.Print("hello")
+	if err := term.Refresh(); err != io.ErrUnexpectedEOF {
+		t.Errorf("Refresh() = %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+	if got := w.String(); got != "\033[H" {
+		t.Errorf("written after the error: %q", got)
+	}
+}
+
+func TestHideCursorHeldAcrossFrames(t *testing.T) {
+	var buf bytes.Buffer
+	term := New(&buf)
+
+	term.HideCursor()
+	term.Print("x")
+	term.Refresh()
+	expected := "\033[?25l\033[H\033[0mx"
+	if got := buf.String(); got != expected {
+		t.Errorf("first frame = %q, want %q", got, expected)
+	}
+
+	buf.Reset()
+	term.Print("y")
+	term.Refresh()
+	if got := buf.String(); got != "y" {
+		t.Errorf("second frame = %q, want %q", got, "y")
+	}
+
+	buf.Reset()
+	term.ShowCursor()
+	term.Refresh()
+	if got := buf.String(); got != "\033[?25h" {
+		t.Errorf("ShowCursor frame = %q, want %q", got, "\033[?25h")
+	}
+}
+
+func TestInvalidateRepaints(t *testing.T) {
+	var buf bytes.Buffer
+	term := New(&buf)
+
+	term.Print("hi")
+	term.Refresh()
+	buf.Reset()
+
+	term.Invalidate()
+	term.Refresh()
+	expected := "\033[0m\033[2J\033[Hhi"
+	if got := buf.String(); got != expected {
+		t.Errorf("Refresh() after Invalidate = %q, want %q", got, expected)
+	}
+}
+
+func TestStylePersistsAcrossPrints(t *testing.T) {
+	var buf bytes.Buffer
+	term := New(&buf)
+
+	term.SetFgColor(Red)
+	term.Print("a")
+	term.Printf("%s", "b")
+	term.Println("c")
+	term.Refresh()
+
+	expected := "\033[H\033[0;31mabc\033[0m\033[2;1H"
+	if got := buf.String(); got != expected {
+		t.Errorf("got %q, want %q", got, expected)
+	}
+}
+
+func TestPushPopStyleNested(t *testing.T) {
+	var buf bytes.Buffer
+	term := New(&buf)
+
+	term.SetFgColor(Blue)
+	term.PushStyle()
+	term.SetBold()
+	term.Print("B")
+	term.PushStyle()
+	term.SetFgColor(Red)
+	term.Print("R")
+	term.PopStyle()
+	term.Print("B")
+	term.PopStyle()
+	term.Print("b")
+	term.PopStyle() // unbalanced pop resets to default
+	term.Print("n")
+	term.Refresh()
+
+	expected := "\033[H\033[0;34;1mB\033[31mR\033[34mB\033[22mb\033[0mn"
+	if got := buf.String(); got != expected {
+		t.Errorf("got %q, want %q", got, expected)
+	}
+}
+
+func TestSetSize(t *testing.T) {
+	var buf bytes.Buffer
+	term := New(&buf)
+	term.SetSize(80, 24)
+
+	term.Move(24, 80)
+	term.Print("x")
+	term.Refresh()
+	buf.Reset()
+
+	term.SetSize(40, 10)
+	if w, h := term.GetTerminalWidth(), term.GetTerminalHeight(); w != 40 || h != 10 {
+		t.Fatalf("size = %dx%d, want 40x10", w, h)
+	}
+	term.Move(1, 1)
+	term.Print("y")
+	term.Refresh()
+	// A resize after the first frame forces a full repaint.
+	expected := "\033[0m\033[2J\033[Hy"
+	if got := buf.String(); got != expected {
+		t.Errorf("frame after resize = %q, want %q", got, expected)
+	}
+}
+
+// TestMain runs the tests in a fixed terminal environment, so that the
+// capabilities New detects do not depend on where the tests are run.
+func TestMain(m *testing.M) {
+	os.Setenv("TERM", "xterm")
+	os.Setenv("LANG", "C.UTF-8")
+	for _, v := range []string{"COLORTERM", "TERM_PROGRAM", "VTE_VERSION", "WT_SESSION", "NO_COLOR", "LC_ALL", "LC_CTYPE"} {
+		os.Unsetenv(v)
+	}
+	os.Exit(m.Run())
+}
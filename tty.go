@@ -0,0 +1,95 @@
+// tty.go — optional terminal control for input sources.
+// Copyright (C) 2025 R. S. Doiel
+package termlib
+
+import (
+	"errors"
+	"io"
+	"sync"
+
+	"golang.org/x/term"
+)
+
+// ErrNotTerminal is returned by EnterRawMode when its input has no
+// terminal to control.
+var ErrNotTerminal = errors.New("not a terminal")
+
+/** TTY controls the terminal behind an input source: switching it into raw
+ * mode and reporting its size. Inputs read by LineEditor, ReadKey and the
+ * other readers do not need to be terminals; a TTY is only needed where
+ * terminal modes matter.
+ *
+ * Files open on a terminal, such as os.Stdin, get a TTY automatically. Other
+ * sources can supply their own, either by implementing TTY on the reader
+ * itself or by setting LineEditor.TTY. For a session served over SSH, raw
+ * mode is the client's business, so MakeRaw can simply do nothing, and Size
+ * reports the size from the session's pty-req and window-change requests.
+ *
+ * Example:
+ *   type sshTTY struct{ sess *session }
+ *   func (s sshTTY) MakeRaw() (func() error, error) { return func() error { return nil }, nil }
+ *   func (s sshTTY) Size() (int, int, error)       { return s.sess.Width(), s.sess.Height(), nil }
+ *
+ *   le := termlib.NewLineEditor(channel, channel)
+ *   le.TTY = sshTTY{sess}
+ */
+type TTY interface {
+	// MakeRaw puts the terminal into raw mode and returns a function that
+	// restores the previous mode.
+	MakeRaw() (restore func() error, err error)
+	// Size returns the terminal's width in columns and height in rows.
+	Size() (width, height int, err error)
+}
+
+// fdTTY is the TTY for a file descriptor open on a terminal.
+type fdTTY int
+
+func (fd fdTTY) MakeRaw() (func() error, error) {
+	st, err := term.MakeRaw(int(fd))
+	if err != nil {
+		return nil, err
+	}
+	return func() error { return term.Restore(int(fd), st) }, nil
+}
+
+func (fd fdTTY) Size() (int, int, error) {
+	return term.GetSize(int(fd))
+}
+
+// makeRaw puts tty into raw mode, registering the change with
+// RestoreTerminal, and returns a function that leaves raw mode again.
+func makeRaw(tty TTY) (func(), error) {
+	undo, err := tty.MakeRaw()
+	if err != nil {
+		return nil, err
+	}
+	var once sync.Once
+	var entry *restorer
+	restore := func() {
+		once.Do(func() {
+			entry.unregister()
+			undo()
+		})
+	}
+	resume := func() {
+		if u, err := tty.MakeRaw(); err == nil {
+			undo = u
+		}
+	}
+	entry = registerRestore(restore, func() { undo() }, resume)
+	return restore, nil
+}
+
+// ttyFor returns the TTY for in: in itself when it implements TTY, a
+// descriptor-based TTY when in is a file open on a terminal, or nil.
+func ttyFor(in io.Reader) TTY {
+	if t, ok := in.(TTY); ok {
+		return t
+	}
+	if f, ok := in.(interface{ Fd() uintptr }); ok {
+		if fd := int(f.Fd()); term.IsTerminal(fd) {
+			return fdTTY(fd)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+// tty_test.go — tests for terminal control of input sources.
+// Copyright (C) 2025 R. S. Doiel
+package termlib
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEnterRawModeNotTerminal(t *testing.T) {
+	restore, err := EnterRawMode(strings.NewReader(""))
+	if err != ErrNotTerminal {
+		t.Errorf("want ErrNotTerminal, got %v", err)
+	}
+	restore() // must be safe to call
+}
+
+// rawReader is a reader that controls its own terminal.
+type rawReader struct {
+	*strings.Reader
+	raw *bool
+}
+
+func (r rawReader) MakeRaw() (func() error, error) {
+	*r.raw = true
+	return func() error { *r.raw = false; return nil }, nil
+}
+
+func (r rawReader) Size() (int, int, error) { return 80, 24, nil }
+
+func TestEnterRawModeUsesReaderTTY(t *testing.T) {
+	raw := false
+	restore, err := EnterRawMode(rawReader{strings.NewReader(""), &raw})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !raw {
+		t.Error("MakeRaw not called")
+	}
+	restore()
+	if raw {
+		t.Error("restore not called")
+	}
+}
@@ -6,24 +6,34 @@ import (
 	"fmt"
 	"strings"
 	"time"
-	"unicode/utf8"
 )
 
-// Box-drawing and bar characters.
-const (
-	boxTopLeft     = "┌"
-	boxTopRight    = "┐"
-	boxBottomLeft  = "└"
-	boxBottomRight = "┘"
-	boxHoriz       = "─"
-	boxVert        = "│"
-	barFull        = "█"
-	barEmpty       = "░"
+// drawChars are the characters the widgets draw with.
+type drawChars struct {
+	topLeft, topRight, bottomLeft, bottomRight string
+	horiz, vert                                string
+	barFull, barEmpty                          string
+}
+
+// Box-drawing and bar characters, and the ASCII used on terminals
+// without Unicode (see Capabilities).
+var (
+	unicodeChars = drawChars{"┌", "┐", "└", "┘", "─", "│", "█", "░"}
+	asciiChars   = drawChars{"+", "+", "+", "+", "-", "|", "#", "."}
 )
 
-/** DrawBox draws a Unicode box at the given terminal position. The box
- * occupies width columns and height rows. title is embedded in the top
- * border; pass an empty string for a plain border.
+// drawChars returns the characters the widgets draw with on t.
+func (t *Terminal) drawChars() drawChars {
+	if t.Capabilities().Unicode {
+		return unicodeChars
+	}
+	return asciiChars
+}
+
+/** DrawBox draws a Unicode box at the given terminal position in the
+ * terminal's current style, or an ASCII one on terminals without Unicode.
+ * The box occupies width columns and height rows. title is embedded in the
+ * top border; pass an empty string for a plain border.
  *
  * Parameters:
  *   t      (*Terminal) — terminal to draw on.
@@ -37,37 +47,64 @@ const (
  *   termlib.DrawBox(term, 1, 1, 40, 10, "Now Playing")
  */
 func DrawBox(t *Terminal, row, col, width, height int, title string) {
+	DrawBoxStyled(t, t.GetStyle(), row, col, width, height, title)
+}
+
+/** DrawBoxStyled is DrawBox with an explicit style for the border and
+ * title. The terminal's current style is left unchanged.
+ *
+ * Parameters:
+ *   t      (*Terminal) — terminal to draw on.
+ *   st     (Style)     — style for the border and title.
+ *   row    (int)       — top row of the box (1-based).
+ *   col    (int)       — left column of the box (1-based).
+ *   width  (int)       — total width including borders.
+ *   height (int)       — total height including borders.
+ *   title  (string)    — optional label in the top border.
+ *
+ * Example:
+ *   frame := termlib.NewStyle().Fg(termlib.Cyan)
+ *   termlib.DrawBoxStyled(term, frame, 1, 1, 40, 10, "Now Playing")
+ */
+func DrawBoxStyled(t *Terminal, st Style, row, col, width, height int, title string) {
+	ch := t.drawChars()
+	t.PushStyle()
+	defer t.PopStyle()
+	t.SetStyle(st)
+
 	// Top border
 	t.Move(row, col)
 	if title != "" {
-		label := "─ " + title + " "
-		labelW := 2 + utf8.RuneCountInString(title) + 1
+		label := ch.horiz + " " + title + " "
+		labelW := 2 + StringWidth(title) + 1
 		remaining := width - 2 - labelW
 		if remaining < 0 {
 			remaining = 0
-			label = Truncate(label, width-2)
+			label = PadRight(label, width-2)
 		}
-		t.Print(boxTopLeft + label + strings.Repeat(boxHoriz, remaining) + boxTopRight)
+		t.Print(ch.topLeft + label + strings.Repeat(ch.horiz, remaining) + ch.topRight)
 	} else {
-		t.Print(boxTopLeft + strings.Repeat(boxHoriz, width-2) + boxTopRight)
+		t.Print(ch.topLeft + strings.Repeat(ch.horiz, width-2) + ch.topRight)
 	}
 
 	// Side borders (interior rows only)
 	for r := row + 1; r < row+height-1; r++ {
 		t.Move(r, col)
-		t.Print(boxVert)
+		t.Print(ch.vert)
 		t.Move(r, col+width-1)
-		t.Print(boxVert)
+		t.Print(ch.vert)
 	}
 
 	// Bottom border
 	t.Move(row+height-1, col)
-	t.Print(boxBottomLeft + strings.Repeat(boxHoriz, width-2) + boxBottomRight)
+	t.Print(ch.bottomLeft + strings.Repeat(ch.horiz, width-2) + ch.bottomRight)
 }
 
-/** DrawProgressBar draws a horizontal progress bar at the given position.
- * The bar renders as [████░░░░] where filled cells represent value/total.
- * width is the total width of the bar including the surrounding brackets.
+/** DrawProgressBar draws a horizontal progress bar at the given position
+ * in the terminal's current style. The bar renders as [████░░░░], or
+ * [####....] on terminals without Unicode, where filled cells represent
+ * value/total. width is the total width of the bar
+ * including the surrounding brackets.
  *
  * Parameters:
  *   t      (*Terminal) — terminal to draw on.
@@ -81,6 +118,26 @@ func DrawBox(t *Terminal, row, col, width, height int, title string) {
  *   termlib.DrawProgressBar(term, 5, 3, 30, elapsed.Seconds(), total.Seconds())
  */
 func DrawProgressBar(t *Terminal, row, col, width int, value, total float64) {
+	DrawProgressBarStyled(t, t.GetStyle(), row, col, width, value, total)
+}
+
+/** DrawProgressBarStyled is DrawProgressBar with an explicit style for the
+ * bar. The terminal's current style is left unchanged.
+ *
+ * Parameters:
+ *   t      (*Terminal) — terminal to draw on.
+ *   st     (Style)     — style for the bar.
+ *   row    (int)       — row (1-based).
+ *   col    (int)       — starting column (1-based).
+ *   width  (int)       — total width including brackets.
+ *   value  (float64)   — current value.
+ *   total  (float64)   — maximum value; bar is empty when total <= 0.
+ *
+ * Example:
+ *   bar := termlib.NewStyle().Fg(termlib.Green)
+ *   termlib.DrawProgressBarStyled(term, bar, 5, 3, 30, done, total)
+ */
+func DrawProgressBarStyled(t *Terminal, st Style, row, col, width int, value, total float64) {
 	inner := width - 2
 	if inner < 1 {
 		inner = 1
@@ -92,55 +149,71 @@ func DrawProgressBar(t *Terminal, row, col, width int, value, total float64) {
 			filled = inner
 		}
 	}
-	bar := "[" + strings.Repeat(barFull, filled) + strings.Repeat(barEmpty, inner-filled) + "]"
+	ch := t.drawChars()
+	bar := "[" + strings.Repeat(ch.barFull, filled) + strings.Repeat(ch.barEmpty, inner-filled) + "]"
 	t.Move(row, col)
-	t.Print(bar)
+	t.PrintStyled(st, bar)
 }
 
-/** Truncate shortens s to at most maxW Unicode code points. If truncation
- * occurs, the last code point is replaced with "…". Returns s unchanged
- * when it fits within maxW.
+/** Truncate shortens s to at most maxW display columns. If truncation
+ * occurs, the text is cut at a grapheme cluster boundary and ends with
+ * "…"; when a wide character would straddle the limit the result is one
+ * column narrower than maxW. Returns s unchanged when it fits within maxW.
  *
  * Parameters:
  *   s    (string) — the string to truncate.
- *   maxW (int)    — maximum number of Unicode code points to return.
+ *   maxW (int)    — maximum display width in columns (see StringWidth).
  *
  * Returns:
  *   string — s, possibly truncated with a trailing "…".
  *
  * Example:
  *   label := termlib.Truncate("Goldberg Variations", 12) // "Goldberg Va…"
+ *   label = termlib.Truncate("日本語テスト", 7)           // "日本語…"
  */
 func Truncate(s string, maxW int) string {
-	runes := []rune(s)
-	if len(runes) <= maxW {
+	if StringWidth(s) <= maxW {
 		return s
 	}
 	if maxW <= 1 {
 		return "…"
 	}
-	return string(runes[:maxW-1]) + "…"
+	var sb strings.Builder
+	w := 0
+	for rest := s; rest != ""; {
+		g, gw := nextGrapheme(rest)
+		if w+gw > maxW-1 {
+			break
+		}
+		sb.WriteString(g)
+		w += gw
+		rest = rest[len(g):]
+	}
+	return sb.String() + "…"
 }
 
-/** PadRight pads s with trailing spaces to exactly w Unicode code points.
- * If s is already longer than w it is truncated with Truncate.
+/** PadRight pads s with trailing spaces to exactly w display columns.
+ * If s is already wider than w it is truncated with Truncate, and padded
+ * again if a wide character left it one column short.
  *
  * Parameters:
  *   s (string) — the string to pad or truncate.
- *   w (int)    — desired display width in Unicode code points.
+ *   w (int)    — desired display width in columns.
  *
  * Returns:
- *   string — s padded or truncated to exactly w code points.
+ *   string — s padded or truncated to exactly w columns.
  *
  * Example:
  *   cell := termlib.PadRight(trackName, columnWidth)
  */
 func PadRight(s string, w int) string {
-	runes := []rune(s)
-	if len(runes) >= w {
-		return Truncate(s, w)
+	if StringWidth(s) > w {
+		s = Truncate(s, w)
+	}
+	if sw := StringWidth(s); sw < w {
+		s += strings.Repeat(" ", w-sw)
 	}
-	return s + strings.Repeat(" ", w-len(runes))
+	return s
 }
 
 /** FormatDuration formats a time.Duration as "m:ss" or "h:mm:ss" for
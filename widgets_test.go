@@ -20,7 +20,11 @@ func TestTruncate(t *testing.T) {
 		{"hello world", 8, "hello w…"},
 		{"hello", 1, "…"},
 		{"", 5, ""},
-		{"日本語テスト", 4, "日本語…"},
+		{"日本語テスト", 4, "日…"},
+		{"日本語テスト", 7, "日本語…"},
+		{"日本語テスト", 6, "日本…"},
+		{"cafe\u0301 noir", 5, "cafe\u0301…"},
+		{"👍🏽👍🏽👍🏽", 5, "👍🏽👍🏽…"},
 	}
 	for _, c := range cases {
 		got := Truncate(c.in, c.maxW)
@@ -40,6 +44,9 @@ func TestPadRight(t *testing.T) {
 		{"hello", 5, "hello"},
 		{"hello world", 5, "hell…"},
 		{"", 3, "   "},
+		{"日本", 5, "日本 "},
+		{"日本語", 5, "日本…"},
+		{"日本語", 4, "日… "},
 	}
 	for _, c := range cases {
 		got := PadRight(c.in, c.w)
@@ -124,3 +131,34 @@ func TestDrawBoxWithTitle(t *testing.T) {
 		t.Errorf("DrawBox title missing, got %q", got)
 	}
 }
+
+func TestDrawBoxStyled(t *testing.T) {
+	var buf bytes.Buffer
+	term := New(&buf)
+
+	DrawBoxStyled(term, NewStyle().Fg(Cyan), 1, 1, 4, 2, "")
+	term.Print("x")
+	term.Refresh()
+	got := buf.String()
+
+	if !strings.Contains(got, "\033[0;36m┌──┐") {
+		t.Errorf("DrawBoxStyled border not styled, got %q", got)
+	}
+	if !strings.Contains(got, "┘\033[0mx") {
+		t.Errorf("style leaked past DrawBoxStyled, got %q", got)
+	}
+}
+
+func TestDrawBoxWideTitle(t *testing.T) {
+	var buf bytes.Buffer
+	term := New(&buf)
+
+	DrawBox(term, 1, 1, 12, 3, "日本")
+	term.Refresh()
+	got := buf.String()
+
+	// "─ 日本 " is 7 columns, leaving 3 for the rule in a 12-wide box.
+	if !strings.Contains(got, "┌─ 日本 ───┐") {
+		t.Errorf("DrawBox wide title misaligned, got %q", got)
+	}
+}
@@ -0,0 +1,330 @@
+// width.go — display width and grapheme clusters for terminal text.
+// Copyright (C) 2025 R. S. Doiel
+package termlib
+
+import "unicode"
+
+// runeRange is an inclusive range of code points.
+type runeRange struct{ lo, hi rune }
+
+// inRanges reports whether r falls in one of the sorted ranges in table.
+func inRanges(r rune, table []runeRange) bool {
+	lo, hi := 0, len(table)
+	for lo < hi {
+		m := (lo + hi) / 2
+		switch {
+		case r < table[m].lo:
+			hi = m
+		case r > table[m].hi:
+			lo = m + 1
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// wideRanges lists the East Asian Wide (W) and Fullwidth (F) code points,
+// which includes the emoji that default to emoji presentation.
+var wideRanges = []runeRange{
+	{0x1100, 0x115F}, {0x231A, 0x231B}, {0x2329, 0x232A}, {0x23E9, 0x23EC},
+	{0x23F0, 0x23F0}, {0x23F3, 0x23F3}, {0x25FD, 0x25FE}, {0x2614, 0x2615},
+	{0x2648, 0x2653}, {0x267F, 0x267F}, {0x2693, 0x2693}, {0x26A1, 0x26A1},
+	{0x26AA, 0x26AB}, {0x26BD, 0x26BE}, {0x26C4, 0x26C5}, {0x26CE, 0x26CE},
+	{0x26D4, 0x26D4}, {0x26EA, 0x26EA}, {0x26F2, 0x26F3}, {0x26F5, 0x26F5},
+	{0x26FA, 0x26FA}, {0x26FD, 0x26FD}, {0x2705, 0x2705}, {0x270A, 0x270B},
+	{0x2728, 0x2728}, {0x274C, 0x274C}, {0x274E, 0x274E}, {0x2753, 0x2755},
+	{0x2757, 0x2757}, {0x2795, 0x2797}, {0x27B0, 0x27B0}, {0x27BF, 0x27BF},
+	{0x2B1B, 0x2B1C}, {0x2B50, 0x2B50}, {0x2B55, 0x2B55}, {0x2E80, 0x303E},
+	{0x3041, 0x33FF}, {0x3400, 0x4DBF}, {0x4E00, 0x9FFF}, {0xA000, 0xA4CF},
+	{0xA960, 0xA97F}, {0xAC00, 0xD7A3}, {0xF900, 0xFAFF}, {0xFE10, 0xFE19},
+	{0xFE30, 0xFE6F}, {0xFF00, 0xFF60}, {0xFFE0, 0xFFE6}, {0x16FE0, 0x16FE4},
+	{0x17000, 0x18CFF}, {0x1B000, 0x1B2FF}, {0x1F004, 0x1F004}, {0x1F0CF, 0x1F0CF},
+	{0x1F18E, 0x1F18E}, {0x1F191, 0x1F19A}, {0x1F200, 0x1F202}, {0x1F210, 0x1F23B},
+	{0x1F240, 0x1F248}, {0x1F250, 0x1F251}, {0x1F260, 0x1F265}, {0x1F300, 0x1F320},
+	{0x1F32D, 0x1F335}, {0x1F337, 0x1F37C}, {0x1F37E, 0x1F393}, {0x1F3A0, 0x1F3CA},
+	{0x1F3CF, 0x1F3D3}, {0x1F3E0, 0x1F3F0}, {0x1F3F4, 0x1F3F4}, {0x1F3F8, 0x1F43E},
+	{0x1F440, 0x1F440}, {0x1F442, 0x1F4FC}, {0x1F4FF, 0x1F53D}, {0x1F54B, 0x1F54E},
+	{0x1F550, 0x1F567}, {0x1F57A, 0x1F57A}, {0x1F595, 0x1F596}, {0x1F5A4, 0x1F5A4},
+	{0x1F5FB, 0x1F64F}, {0x1F680, 0x1F6C5}, {0x1F6CC, 0x1F6CC}, {0x1F6D0, 0x1F6D2},
+	{0x1F6D5, 0x1F6D7}, {0x1F6DC, 0x1F6DF}, {0x1F6EB, 0x1F6EC}, {0x1F6F4, 0x1F6FC},
+	{0x1F7E0, 0x1F7EB}, {0x1F7F0, 0x1F7F0}, {0x1F90C, 0x1F93A}, {0x1F93C, 0x1F945},
+	{0x1F947, 0x1F9FF}, {0x1FA70, 0x1FA7C}, {0x1FA80, 0x1FA89}, {0x1FA8F, 0x1FAC6},
+	{0x1FACE, 0x1FADC}, {0x1FADF, 0x1FAE9}, {0x1FAF0, 0x1FAF8}, {0x20000, 0x2FFFD},
+	{0x30000, 0x3FFFD},
+}
+
+// pictographicRanges approximates Extended_Pictographic, the code points
+// that can be joined into emoji sequences with a zero-width joiner.
+var pictographicRanges = []runeRange{
+	{0x00A9, 0x00A9}, {0x00AE, 0x00AE}, {0x203C, 0x203C}, {0x2049, 0x2049},
+	{0x2122, 0x2122}, {0x2139, 0x2139}, {0x2194, 0x2199}, {0x21A9, 0x21AA},
+	{0x231A, 0x231B}, {0x2328, 0x2328}, {0x23CF, 0x23CF}, {0x23E9, 0x23F3},
+	{0x23F8, 0x23FA}, {0x24C2, 0x24C2}, {0x25AA, 0x25AB}, {0x25B6, 0x25B6},
+	{0x25C0, 0x25C0}, {0x25FB, 0x25FE}, {0x2600, 0x27BF}, {0x2934, 0x2935},
+	{0x2B05, 0x2B07}, {0x2B1B, 0x2B1C}, {0x2B50, 0x2B50}, {0x2B55, 0x2B55},
+	{0x3030, 0x3030}, {0x303D, 0x303D}, {0x3297, 0x3297}, {0x3299, 0x3299},
+	{0x1F000, 0x1F1E5}, {0x1F200, 0x1F3FA}, {0x1F400, 0x1FAFF}, {0x1FC00, 0x1FFFD},
+}
+
+const (
+	zwj        = 0x200D // zero-width joiner
+	vs15       = 0xFE0E // variation selector: text presentation
+	vs16       = 0xFE0F // variation selector: emoji presentation
+	riFirst    = 0x1F1E6
+	riLast     = 0x1F1FF
+	hangulBase = 0xAC00
+	hangulLast = 0xD7A3
+)
+
+// isRegionalIndicator reports whether r is one of the letters used in
+// pairs to form flag emoji.
+func isRegionalIndicator(r rune) bool {
+	return r >= riFirst && r <= riLast
+}
+
+// isExtend reports whether r extends the preceding grapheme cluster:
+// combining and spacing marks, joiners, emoji skin tone modifiers and
+// tag characters.
+func isExtend(r rune) bool {
+	switch {
+	case r == zwj || r == 0x200C:
+		return true
+	case r >= 0x1F3FB && r <= 0x1F3FF:
+		return true
+	case r >= 0xE0020 && r <= 0xE007F:
+		return true
+	}
+	return unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc)
+}
+
+// isControl reports whether r always stands alone as a grapheme cluster.
+func isControl(r rune) bool {
+	if r < 0x20 || (r >= 0x7F && r < 0xA0) || r == 0x2028 || r == 0x2029 {
+		return true
+	}
+	return unicode.Is(unicode.Cf, r) && !isExtend(r)
+}
+
+// hangulType classifies Hangul jamo and syllables for grapheme breaking.
+type hangulType int
+
+const (
+	hangulNone hangulType = iota
+	hangulL
+	hangulV
+	hangulT
+	hangulLV
+	hangulLVT
+)
+
+// hangulKind returns the Hangul syllable type of r.
+func hangulKind(r rune) hangulType {
+	switch {
+	case (r >= 0x1100 && r <= 0x115F) || (r >= 0xA960 && r <= 0xA97C):
+		return hangulL
+	case (r >= 0x1160 && r <= 0x11A7) || (r >= 0xD7B0 && r <= 0xD7C6):
+		return hangulV
+	case (r >= 0x11A8 && r <= 0x11FF) || (r >= 0xD7CB && r <= 0xD7FB):
+		return hangulT
+	case r >= hangulBase && r <= hangulLast:
+		if (r-hangulBase)%28 == 0 {
+			return hangulLV
+		}
+		return hangulLVT
+	}
+	return hangulNone
+}
+
+/** RuneWidth returns the number of terminal columns r occupies when it
+ * stands alone: 2 for East Asian wide and fullwidth characters and for
+ * emoji, 0 for combining marks, joiners and other zero-width characters,
+ * and 1 otherwise. Control characters report 0.
+ *
+ * Parameters:
+ *   r (rune) — the code point to measure.
+ *
+ * Returns:
+ *   int — display width in columns.
+ *
+ * Example:
+ *   termlib.RuneWidth('中') // 2
+ */
+func RuneWidth(r rune) int {
+	switch {
+	case r < 0x20 || (r >= 0x7F && r < 0xA0):
+		return 0
+	case r < 0x300:
+		return 1
+	case isExtend(r) && !unicode.Is(unicode.Mc, r):
+		return 0
+	case unicode.Is(unicode.Cf, r):
+		return 0
+	case r >= 0x1160 && r <= 0x11FF: // Hangul medial vowels and final consonants
+		return 0
+	case inRanges(r, wideRanges):
+		return 2
+	}
+	return 1
+}
+
+// graphemeState carries the context needed to find cluster boundaries
+// while scanning text one code point at a time.
+type graphemeState struct {
+	prev    rune
+	started bool
+	riCount int  // consecutive regional indicators ending at prev
+	pictSeq bool // prev ends a pictographic Extend* sequence (GB11)
+}
+
+// breakBefore reports whether a grapheme cluster boundary falls before r,
+// following the extended grapheme cluster rules of Unicode UAX #29, and
+// advances the state past r.
+func (st *graphemeState) breakBefore(r rune) bool {
+	prev := st.prev
+	brk := true
+	switch {
+	case !st.started:
+		brk = true
+	case prev == '\r' && r == '\n': // GB3
+		brk = false
+	case isControl(prev) || isControl(r): // GB4, GB5
+		brk = true
+	case hangulJoins(hangulKind(prev), hangulKind(r)): // GB6–GB8
+		brk = false
+	case isExtend(r): // GB9, GB9a
+		brk = false
+	case prev == zwj && st.pictSeq && inRanges(r, pictographicRanges): // GB11
+		brk = false
+	case isRegionalIndicator(prev) && isRegionalIndicator(r) && st.riCount%2 == 1: // GB12, GB13
+		brk = false
+	}
+
+	if isRegionalIndicator(r) {
+		if brk {
+			st.riCount = 0
+		}
+		st.riCount++
+	} else {
+		st.riCount = 0
+	}
+	switch {
+	case inRanges(r, pictographicRanges) && !isRegionalIndicator(r):
+		st.pictSeq = true
+	case isExtend(r):
+		// An Extend or ZWJ continues a pictographic sequence.
+	default:
+		st.pictSeq = false
+	}
+	st.prev, st.started = r, true
+	return brk
+}
+
+// hangulJoins reports whether Hangul types a and b belong to one syllable.
+func hangulJoins(a, b hangulType) bool {
+	switch a {
+	case hangulL:
+		return b == hangulL || b == hangulV || b == hangulLV || b == hangulLVT
+	case hangulLV, hangulV:
+		return b == hangulV || b == hangulT
+	case hangulLVT, hangulT:
+		return b == hangulT
+	}
+	return false
+}
+
+// clusterWidth returns the display width of the grapheme cluster held in
+// runes. The cluster takes the width of its first code point, widened to
+// 2 by an emoji presentation selector or a regional indicator pair and
+// narrowed to 1 by a text presentation selector. Spacing marks, such as
+// the Devanagari vowel signs, add a column each, as they do in terminals
+// that measure text with wcwidth.
+func clusterWidth(runes []rune) int {
+	if len(runes) == 0 {
+		return 0
+	}
+	base := runes[0]
+	if isRegionalIndicator(base) && len(runes) > 1 && isRegionalIndicator(runes[1]) {
+		return 2
+	}
+	w, marks := RuneWidth(base), 0
+	for _, r := range runes[1:] {
+		switch {
+		case r == vs16 && inRanges(base, pictographicRanges):
+			w = 2
+		case r == vs15 && inRanges(base, pictographicRanges):
+			w = 1
+		case unicode.Is(unicode.Mc, r):
+			marks++
+		}
+	}
+	if w == 1 {
+		w += marks
+	}
+	return w
+}
+
+// nextGrapheme returns the first grapheme cluster of s and its display
+// width.
+func nextGrapheme(s string) (cluster string, width int) {
+	var st graphemeState
+	var runes []rune
+	for i, r := range s {
+		if st.breakBefore(r) && i > 0 {
+			return s[:i], clusterWidth(runes)
+		}
+		runes = append(runes, r)
+	}
+	return s, clusterWidth(runes)
+}
+
+// graphemeLen returns the number of runes in the grapheme cluster that
+// starts at runes[0].
+func graphemeLen(runes []rune) int {
+	var st graphemeState
+	for i, r := range runes {
+		if st.breakBefore(r) && i > 0 {
+			return i
+		}
+	}
+	return len(runes)
+}
+
+/** StringWidth returns the number of terminal columns s occupies, measuring
+ * each grapheme cluster (a base character together with its combining
+ * marks, variation selectors, skin tones or zero-width-joined emoji)
+ * as a single character of width 0, 1 or 2.
+ *
+ * Parameters:
+ *   s (string) — the text to measure; it should contain no escape
+ *                sequences or control characters.
+ *
+ * Returns:
+ *   int — display width in columns.
+ *
+ * Example:
+ *   termlib.StringWidth("日本")   // 4
+ *   termlib.StringWidth("é")     // 1, whether precomposed or e + U+0301
+ *   termlib.StringWidth("👩‍💻") // 2
+ */
+func StringWidth(s string) int {
+	w := 0
+	for s != "" {
+		g, gw := nextGrapheme(s)
+		w += gw
+		s = s[len(g):]
+	}
+	return w
+}
+
+// runesWidth returns the display width of runes, measured by grapheme
+// cluster like StringWidth.
+func runesWidth(runes []rune) int {
+	w := 0
+	for len(runes) > 0 {
+		n := graphemeLen(runes)
+		w += clusterWidth(runes[:n])
+		runes = runes[n:]
+	}
+	return w
+}
@@ -0,0 +1,113 @@
+// width_test.go — tests for display width and grapheme clusters.
+// Copyright (C) 2025 R. S. Doiel
+package termlib
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRuneWidth(t *testing.T) {
+	cases := []struct {
+		r    rune
+		want int
+	}{
+		{'a', 1},
+		{'é', 1},
+		{'─', 1},
+		{'中', 2},
+		{'カ', 2},
+		{'ｶ', 1}, // halfwidth katakana
+		{'Ａ', 2}, // fullwidth A
+		{'한', 2},
+		{'😀', 2},
+		{'́', 0}, // combining acute accent
+		{'‍', 0}, // zero-width joiner
+		{'️', 0}, // variation selector-16
+		{'\t', 0},
+	}
+	for _, c := range cases {
+		if got := RuneWidth(c.r); got != c.want {
+			t.Errorf("RuneWidth(%U) = %d, want %d", c.r, got, c.want)
+		}
+	}
+}
+
+func TestStringWidth(t *testing.T) {
+	cases := []struct {
+		name string
+		s    string
+		want int
+	}{
+		{"ascii", "hello", 5},
+		{"cjk", "日本語", 6},
+		{"mixed", "a中b", 4},
+		{"combining mark", "é", 1},
+		{"vietnamese stacked marks", "Việt", 4},
+		{"devanagari spacing marks", "हिन्दी", 5},
+		{"flag", "🇯🇵", 2},
+		{"two flags", "🇯🇵🇫🇷", 4},
+		{"zwj family", "👨‍👩‍👧", 2},
+		{"skin tone", "👍🏽", 2},
+		{"emoji presentation selector", "❤️", 2},
+		{"text presentation", "❤", 1},
+		{"hangul jamo", "각", 2},
+	}
+	for _, c := range cases {
+		if got := StringWidth(c.s); got != c.want {
+			t.Errorf("%s: StringWidth(%q) = %d, want %d", c.name, c.s, got, c.want)
+		}
+	}
+}
+
+func TestNextGrapheme(t *testing.T) {
+	cases := []struct {
+		s, want string
+	}{
+		{"abc", "a"},
+		{"éx", "é"},
+		{"\r\nx", "\r\n"},
+		{"🇯🇵🇫🇷", "🇯🇵"},
+		{"👨‍👩‍👧!", "👨‍👩‍👧"},
+		{"👍🏽x", "👍🏽"},
+		{"각x", "각"},
+	}
+	for _, c := range cases {
+		if got, _ := nextGrapheme(c.s); got != c.want {
+			t.Errorf("nextGrapheme(%q) = %q, want %q", c.s, got, c.want)
+		}
+	}
+}
+
+func TestPrintWideCursorTracking(t *testing.T) {
+	var buf bytes.Buffer
+	term := New(&buf)
+
+	term.Print("日本é👍🏽x")
+	if _, col := term.GetCurPos(); col != 9 {
+		t.Errorf("cursor column = %d, want 9", col)
+	}
+	term.Refresh()
+	expected := "\033[H\033[0m日本é👍🏽x"
+	if got := buf.String(); got != expected {
+		t.Errorf("got %q, want %q", got, expected)
+	}
+}
+
+func TestPrintOverwriteWideHalf(t *testing.T) {
+	var buf bytes.Buffer
+	term := New(&buf)
+
+	term.Print("日本")
+	term.Refresh()
+	buf.Reset()
+
+	// Writing over the right half of 日 blanks its left half.
+	term.Move(1, 2)
+	term.Print("x")
+	term.Refresh()
+	expected := "\033[H x"
+	if got := buf.String(); got != expected {
+		t.Errorf("got %q, want %q", got, expected)
+	}
+}